@@ -0,0 +1,198 @@
+package circuitbreaker
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAdaptiveK       = 2
+	defaultAdaptiveWindow  = 2 * time.Minute
+	defaultAdaptiveBuckets = 10
+)
+
+// Executor is the call surface shared by CircuitBreaker, AdaptiveThrottle and
+// Bulkhead, so callers can switch between the binary and the adaptive model
+// and chain them.
+type Executor interface {
+	Execute(fn func() error) error
+}
+
+var (
+	_ Executor = (*CircuitBreaker)(nil)
+	_ Executor = (*AdaptiveThrottle)(nil)
+	_ Executor = (*Bulkhead)(nil)
+)
+
+// AdaptiveThrottle is a client-side adaptive throttle as described in the
+// Google SRE book. Instead of switching between Closed and Open, it tracks
+// requests and accepted requests over a rolling window and rejects calls
+// locally with probability
+//
+//	max(0, (requests - K*accepts) / (requests + 1))
+//
+// so that load on a struggling dependency degrades smoothly instead of flapping.
+// Rejections are reported as an *OpenError with StateOpen.
+type AdaptiveThrottle struct {
+	mu          sync.Mutex
+	name        string
+	k           float64
+	bucketWidth time.Duration
+	buckets     []adaptiveBucket // ring of consecutive time slices covering the window
+	classify    func(err error) Outcome
+	nowFunc     func() time.Time // injectable clock for testing
+	randFunc    func() float64   // injectable randomness for testing
+}
+
+type adaptiveBucket struct {
+	start    time.Time
+	requests float64
+	accepts  float64
+}
+
+// AdaptiveOption configures an AdaptiveThrottle.
+type AdaptiveOption func(*AdaptiveThrottle)
+
+// WithAdaptiveName sets the name reported in rejection errors.
+func WithAdaptiveName(name string) AdaptiveOption {
+	return func(at *AdaptiveThrottle) {
+		at.name = name
+	}
+}
+
+// WithK sets the multiplier applied to accepts. Lower values throttle more
+// aggressively; 2 lets through roughly twice as many requests as the backend
+// currently accepts. Default: 2.
+func WithK(k float64) AdaptiveOption {
+	return func(at *AdaptiveThrottle) {
+		if k > 0 {
+			at.k = k
+		}
+	}
+}
+
+// WithWindow sets the rolling window over which requests and accepts are
+// tracked. Windows shorter than 10ns are rounded up to 10ns. Default: 2m.
+func WithWindow(d time.Duration) AdaptiveOption {
+	return func(at *AdaptiveThrottle) {
+		if d > 0 {
+			at.bucketWidth = max(d/defaultAdaptiveBuckets, 1)
+		}
+	}
+}
+
+// WithAdaptiveClassifier sets how Execute maps the error returned by fn to an
+// Outcome. Ignored outcomes are not tracked. Default: DefaultClassifier.
+func WithAdaptiveClassifier(fn func(err error) Outcome) AdaptiveOption {
+	return func(at *AdaptiveThrottle) {
+		at.classify = fn
+	}
+}
+
+// NewAdaptive creates an AdaptiveThrottle with the given options.
+func NewAdaptive(opts ...AdaptiveOption) *AdaptiveThrottle {
+	at := &AdaptiveThrottle{
+		k:           defaultAdaptiveK,
+		bucketWidth: defaultAdaptiveWindow / defaultAdaptiveBuckets,
+		buckets:     make([]adaptiveBucket, defaultAdaptiveBuckets),
+		classify:    DefaultClassifier,
+		nowFunc:     time.Now,
+		randFunc:    rand.Float64, //nolint:gosec // throttling does not need crypto rand
+	}
+
+	for _, opt := range opts {
+		opt(at)
+	}
+
+	return at
+}
+
+// Execute runs fn unless the throttle rejects it locally.
+// Returns an *OpenError when the call is rejected.
+func (at *AdaptiveThrottle) Execute(fn func() error) error {
+	at.mu.Lock()
+	bucket := at.current(at.nowFunc())
+	if at.randFunc() < at.rejectProbability() {
+		// Locally rejected calls still count as requests, which keeps the
+		// throttle engaged while the backend is failing.
+		bucket.requests++
+		at.mu.Unlock()
+		return &OpenError{Name: at.name, State: StateOpen}
+	}
+	at.mu.Unlock()
+
+	err := fn()
+	outcome := at.classify(err)
+
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	bucket = at.current(at.nowFunc())
+	switch outcome {
+	case OutcomeSuccess:
+		bucket.requests++
+		bucket.accepts++
+	case OutcomeFailure:
+		bucket.requests++
+	case OutcomeIgnored, OutcomeRejected:
+	}
+
+	return err
+}
+
+// RejectProbability returns the probability with which the next call is rejected.
+func (at *AdaptiveThrottle) RejectProbability() float64 {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	at.current(at.nowFunc())
+	return at.rejectProbability()
+}
+
+// Stats returns the requests and accepts tracked over the current window.
+func (at *AdaptiveThrottle) Stats() (requests, accepts float64) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	at.current(at.nowFunc())
+	return at.totals()
+}
+
+// rejectProbability computes the SRE client-side throttling formula.
+// Callers must hold mu.
+func (at *AdaptiveThrottle) rejectProbability() float64 {
+	requests, accepts := at.totals()
+	return max(0, (requests-at.k*accepts)/(requests+1))
+}
+
+// totals sums the buckets that are still inside the window. Callers must hold mu.
+func (at *AdaptiveThrottle) totals() (requests, accepts float64) {
+	for _, bucket := range at.buckets {
+		requests += bucket.requests
+		accepts += bucket.accepts
+	}
+
+	return requests, accepts
+}
+
+// current returns the bucket for now, clearing buckets that fell out of the
+// window. Callers must hold mu.
+func (at *AdaptiveThrottle) current(now time.Time) *adaptiveBucket {
+	start := now.Truncate(at.bucketWidth)
+	window := at.bucketWidth * time.Duration(len(at.buckets))
+
+	for i := range at.buckets {
+		if now.Sub(at.buckets[i].start) >= window {
+			at.buckets[i] = adaptiveBucket{}
+		}
+	}
+
+	idx := int(start.UnixNano()/int64(at.bucketWidth)) % len(at.buckets)
+	bucket := &at.buckets[idx]
+	if !bucket.start.Equal(start) {
+		*bucket = adaptiveBucket{start: start}
+	}
+
+	return bucket
+}
@@ -0,0 +1,141 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+func TestAdaptiveThrottle_NeverRejectsHealthyBackend(t *testing.T) {
+	t.Parallel()
+
+	at := circuitbreaker.NewAdaptive()
+
+	for range 100 {
+		if err := at.Execute(func() error { return nil }); err != nil {
+			t.Fatalf("expected no rejection for a healthy backend, got %v", err)
+		}
+	}
+
+	if p := at.RejectProbability(); p != 0 {
+		t.Fatalf("expected reject probability 0, got %v", p)
+	}
+	if requests, accepts := at.Stats(); requests != 100 || accepts != 100 {
+		t.Fatalf("expected 100 requests and accepts, got %v/%v", requests, accepts)
+	}
+}
+
+func TestAdaptiveThrottle_RejectsFailingBackend(t *testing.T) {
+	t.Parallel()
+
+	at := circuitbreaker.NewAdaptive(circuitbreaker.WithAdaptiveName("search"), circuitbreaker.WithK(1))
+
+	// With no accepts, the first call always goes through: p = 0/1.
+	for range 10 {
+		_ = at.Execute(func() error { return errDependency })
+	}
+
+	if p := at.RejectProbability(); math.Abs(p-10.0/11.0) > 1e-9 {
+		t.Fatalf("expected reject probability 10/11, got %v", p)
+	}
+
+	rejected := 0
+	for range 50 {
+		err := at.Execute(func() error { return errDependency })
+		if errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			rejected++
+
+			var openErr *circuitbreaker.OpenError
+			if !errors.As(err, &openErr) || openErr.Name != "search" {
+				t.Fatalf("expected *OpenError for search, got %v", err)
+			}
+		}
+	}
+
+	if rejected == 0 {
+		t.Fatal("expected the throttle to reject some calls")
+	}
+}
+
+func TestAdaptiveThrottle_DegradesSmoothly(t *testing.T) {
+	t.Parallel()
+
+	at := circuitbreaker.NewAdaptive(circuitbreaker.WithK(2))
+
+	// Half of the requests succeed: requests - 2*accepts = 0, so nothing is rejected.
+	for i := range 20 {
+		_ = at.Execute(func() error {
+			if i%2 == 0 {
+				return nil
+			}
+			return errDependency
+		})
+	}
+
+	if p := at.RejectProbability(); p != 0 {
+		t.Fatalf("expected no throttling at a 50%% success rate with K=2, got %v", p)
+	}
+}
+
+func TestAdaptiveThrottle_WindowExpires(t *testing.T) {
+	t.Parallel()
+
+	at := circuitbreaker.NewAdaptive(circuitbreaker.WithWindow(50 * time.Millisecond))
+
+	for range 10 {
+		_ = at.Execute(func() error { return errDependency })
+	}
+	if at.RejectProbability() == 0 {
+		t.Fatal("expected throttling after failures")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if p := at.RejectProbability(); p != 0 {
+		t.Fatalf("expected failures to leave the window, got %v", p)
+	}
+}
+
+func TestAdaptiveThrottle_TinyWindow(t *testing.T) {
+	t.Parallel()
+
+	for _, window := range []time.Duration{time.Nanosecond, 9 * time.Nanosecond} {
+		at := circuitbreaker.NewAdaptive(circuitbreaker.WithWindow(window))
+		if err := at.Execute(func() error { return nil }); err != nil {
+			t.Fatalf("window %v: expected no error, got %v", window, err)
+		}
+		_ = at.RejectProbability()
+	}
+}
+
+func TestAdaptiveThrottle_IgnoredOutcomesAreNotTracked(t *testing.T) {
+	t.Parallel()
+
+	at := circuitbreaker.NewAdaptive(circuitbreaker.WithAdaptiveClassifier(func(error) circuitbreaker.Outcome {
+		return circuitbreaker.OutcomeIgnored
+	}))
+
+	for range 10 {
+		_ = at.Execute(func() error { return errDependency })
+	}
+
+	if requests, _ := at.Stats(); requests != 0 {
+		t.Fatalf("expected ignored calls not to be tracked, got %v requests", requests)
+	}
+}
+
+func TestExecutor_SharedSurface(t *testing.T) {
+	t.Parallel()
+
+	for _, executor := range []circuitbreaker.Executor{
+		circuitbreaker.New(),
+		circuitbreaker.NewAdaptive(),
+	} {
+		if err := executor.Execute(func() error { return nil }); err != nil {
+			t.Fatalf("expected no error from %T, got %v", executor, err)
+		}
+	}
+}
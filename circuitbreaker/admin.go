@@ -0,0 +1,121 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Admin actions accepted by AdminHandler.
+const (
+	ActionForceOpen   = "force-open"
+	ActionForceClosed = "force-closed"
+	ActionClear       = "clear"
+	ActionReset       = "reset"
+)
+
+// Lister provides the breakers exposed by AdminHandler.
+type Lister interface {
+	Breakers() []*CircuitBreaker
+}
+
+// Breakers is a fixed list of breakers that implements Lister.
+type Breakers []*CircuitBreaker
+
+// Breakers implements Lister.
+func (b Breakers) Breakers() []*CircuitBreaker {
+	return b
+}
+
+// breakerStatus is the JSON representation of a breaker served by AdminHandler.
+type breakerStatus struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Counts  Counts `json:"counts"`
+	Timeout string `json:"timeout"`
+}
+
+// AdminHandler returns an http.Handler for operating breakers during incidents.
+//
+//   - GET lists every breaker from lister with its state and counts as JSON,
+//     sorted by name.
+//   - POST with form values "name" and "action" applies an action to the named
+//     breaker and responds with its new status. Actions are force-open,
+//     force-closed, clear (ClearOverride) and reset.
+//
+// Breakers are identified by the name set with WithName; Group breakers are
+// named after their key. The handler performs no authentication, so mount it
+// on an internal or protected listener.
+func AdminHandler(lister Lister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			breakers := lister.Breakers()
+			statuses := make([]breakerStatus, 0, len(breakers))
+			for _, cb := range breakers {
+				statuses = append(statuses, cb.status())
+			}
+			slices.SortFunc(statuses, func(a, b breakerStatus) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+
+			writeJSON(w, http.StatusOK, statuses)
+		case http.MethodPost:
+			handleAction(w, r, lister)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// handleAction applies the requested action to the named breaker.
+func handleAction(w http.ResponseWriter, r *http.Request, lister Lister) {
+	name := r.FormValue("name")
+
+	breakers := lister.Breakers()
+	idx := slices.IndexFunc(breakers, func(cb *CircuitBreaker) bool {
+		return cb.Name() == name
+	})
+	if idx < 0 {
+		http.Error(w, "unknown breaker "+name, http.StatusNotFound)
+		return
+	}
+	cb := breakers[idx]
+
+	switch action := r.FormValue("action"); action {
+	case ActionForceOpen:
+		cb.ForceOpen()
+	case ActionForceClosed:
+		cb.ForceClosed()
+	case ActionClear:
+		cb.ClearOverride()
+	case ActionReset:
+		cb.Reset()
+	default:
+		http.Error(w, "unknown action "+action, http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cb.status())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v) //nolint:errchkjson // nothing to do once headers are written
+}
+
+// status returns the breaker's current status for AdminHandler.
+func (cb *CircuitBreaker) status() breakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return breakerStatus{
+		Name:    cb.name,
+		State:   cb.state.String(),
+		Counts:  cb.counts,
+		Timeout: cb.openTimeout.String(),
+	}
+}
@@ -0,0 +1,149 @@
+package circuitbreaker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+type adminStatus struct {
+	Name   string                `json:"name"`
+	State  string                `json:"state"`
+	Counts circuitbreaker.Counts `json:"counts"`
+}
+
+func postAction(t *testing.T, handler http.Handler, name, action string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	form := url.Values{"name": {name}, "action": {action}}
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAdminHandler_ListsBreakers(t *testing.T) {
+	t.Parallel()
+
+	payments := circuitbreaker.New(circuitbreaker.WithName("payments"), circuitbreaker.WithThreshold(3))
+	search := circuitbreaker.New(circuitbreaker.WithName("search"))
+	_ = payments.Execute(func() error { return errDependency })
+
+	handler := circuitbreaker.AdminHandler(circuitbreaker.Breakers{search, payments})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var statuses []adminStatus
+	if err := json.NewDecoder(rec.Body).Decode(&statuses); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "payments" || statuses[1].Name != "search" {
+		t.Fatalf("expected breakers sorted by name, got %+v", statuses)
+	}
+	if statuses[0].State != "closed" || statuses[0].Counts.ConsecutiveFailures != 1 {
+		t.Fatalf("expected payments closed with 1 failure, got %+v", statuses[0])
+	}
+}
+
+func TestAdminHandler_AppliesOverrides(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup[string]()
+	cb := group.Get("payments")
+	handler := circuitbreaker.AdminHandler(group)
+
+	steps := []struct {
+		action string
+		want   circuitbreaker.State
+	}{
+		{circuitbreaker.ActionForceOpen, circuitbreaker.StateForcedOpen},
+		{circuitbreaker.ActionClear, circuitbreaker.StateClosed},
+		{circuitbreaker.ActionForceClosed, circuitbreaker.StateForcedClosed},
+		{circuitbreaker.ActionReset, circuitbreaker.StateClosed},
+	}
+
+	for _, step := range steps {
+		rec := postAction(t, handler, "payments", step.action)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", step.action, rec.Code, rec.Body)
+		}
+
+		var status adminStatus
+		if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", step.action, err)
+		}
+		if status.State != step.want.String() || cb.State() != step.want {
+			t.Fatalf("%s: expected %v, got %s / %v", step.action, step.want, status.State, cb.State())
+		}
+	}
+}
+
+func TestAdminHandler_TargetsNamedGroupBreaker(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup[string]()
+	for i := range 20 {
+		group.Get(fmt.Sprintf("svc%d", i))
+	}
+	handler := circuitbreaker.AdminHandler(group)
+
+	for range 20 {
+		if rec := postAction(t, handler, "svc5", circuitbreaker.ActionForceOpen); rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+
+		for key, state := range group.States() {
+			if key != "svc5" && state != circuitbreaker.StateClosed {
+				t.Fatalf("expected only svc5 to be forced open, got %s %v", key, state)
+			}
+		}
+		if state := group.Get("svc5").State(); state != circuitbreaker.StateForcedOpen {
+			t.Fatalf("expected svc5 forced open, got %v", state)
+		}
+
+		postAction(t, handler, "svc5", circuitbreaker.ActionClear)
+	}
+
+	breakers := group.Breakers()
+	if !slices.IsSortedFunc(breakers, func(a, b *circuitbreaker.CircuitBreaker) int {
+		return strings.Compare(a.Name(), b.Name())
+	}) {
+		t.Fatal("expected Group.Breakers sorted by name")
+	}
+}
+
+func TestAdminHandler_Errors(t *testing.T) {
+	t.Parallel()
+
+	handler := circuitbreaker.AdminHandler(circuitbreaker.Breakers{
+		circuitbreaker.New(circuitbreaker.WithName("payments")),
+	})
+
+	if rec := postAction(t, handler, "unknown", circuitbreaker.ActionForceOpen); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown breaker, got %d", rec.Code)
+	}
+	if rec := postAction(t, handler, "payments", "explode"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown action, got %d", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodDelete, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for DELETE, got %d", rec.Code)
+	}
+}
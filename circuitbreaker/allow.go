@@ -0,0 +1,175 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Outcome is the result of a guarded call, reported through the done
+// function returned by Allow.
+type Outcome int
+
+const (
+	// OutcomeSuccess counts the call as a success.
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure counts the call as a failure.
+	OutcomeFailure
+	// OutcomeIgnored releases the call's slot without counting it, e.g. for
+	// calls cancelled by the caller.
+	OutcomeIgnored
+	// OutcomeRejected is reported to WithOnCall for calls the breaker
+	// rejected. Passed to done, it behaves like OutcomeIgnored.
+	OutcomeRejected
+)
+
+// String returns the lower-case name of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeIgnored:
+		return "ignored"
+	case OutcomeRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+}
+
+// DefaultClassifier counts nil errors as successes and everything else as failures.
+func DefaultClassifier(err error) Outcome {
+	if err != nil {
+		return OutcomeFailure
+	}
+
+	return OutcomeSuccess
+}
+
+// Allow reserves a slot for a call, including a Half-Open probe slot, without
+// running it. The caller must report the result by calling done exactly once,
+// from any goroutine; further calls are no-ops. Returns an *OpenError when
+// the call is rejected.
+//
+// A probe slot whose done is not called within the probe timeout is released
+// and its late outcome is ignored, so a forgotten done cannot keep the breaker
+// Half-Open forever.
+func (cb *CircuitBreaker) Allow() (done func(outcome Outcome), err error) {
+	start := cb.nowFunc()
+
+	cb.sync(start)
+
+	generation, probe, err := cb.admit(start)
+	if err != nil {
+		if cb.onCall != nil {
+			cb.onCall(OutcomeRejected, 0)
+		}
+		return nil, err
+	}
+
+	var once sync.Once
+
+	return func(outcome Outcome) {
+		once.Do(func() {
+			cb.record(generation, probe, outcome)
+			if cb.onCall != nil {
+				cb.onCall(outcome, cb.nowFunc().Sub(start))
+			}
+		})
+	}, nil
+}
+
+// admit decides whether a call may start, reserving a probe slot in
+// Half-Open. It returns the generation the call belongs to and its probe id
+// (0 outside Half-Open).
+func (cb *CircuitBreaker) admit(now time.Time) (generation, probe uint64, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			cb.counts.Rejections++
+			cb.emit(EventCallRejected, cb.state, cb.state)
+		} else {
+			cb.counts.Requests++
+		}
+	}()
+
+	// Evaluate current state, possibly transitioning Open → Half-Open.
+	switch cb.state {
+	case StateForcedOpen:
+		return 0, 0, cb.openError()
+	case StateOpen:
+		// Wait for an in-flight sync, which may report the dependency still broken.
+		if now.Sub(cb.lastFailure) < cb.openTimeout || cb.syncing {
+			return 0, 0, cb.openError()
+		}
+		cb.transitionTo(StateHalfOpen)
+	case StateClosed, StateHalfOpen, StateForcedClosed:
+	}
+
+	if cb.state == StateHalfOpen {
+		// Allow if completed and in-flight probes stay within halfOpenMax.
+		cb.expireProbes(now)
+		if cb.successes+len(cb.probes) >= cb.halfOpenMax {
+			return 0, 0, cb.openError()
+		}
+
+		cb.nextProbe++
+		probe = cb.nextProbe
+		cb.probes[probe] = now.Add(cb.probeTimeout)
+		cb.emit(EventProbeStarted, cb.state, cb.state)
+	}
+
+	return cb.generation, probe, nil
+}
+
+// record applies the outcome of a call admitted in generation. Every outcome
+// is counted, but outcomes from an earlier generation or from an expired probe
+// lease do not affect the state.
+func (cb *CircuitBreaker) record(generation, probe uint64, outcome Outcome) {
+	cb.mu.Lock()
+	cb.apply(generation, probe, outcome)
+	pending := cb.pending
+	cb.pending = nil
+	cb.mu.Unlock()
+
+	if pending != nil {
+		cb.publish(*pending)
+	}
+}
+
+// apply counts outcome and updates the state. Callers must hold mu.
+func (cb *CircuitBreaker) apply(generation, probe uint64, outcome Outcome) {
+	cb.countOutcome(outcome)
+
+	if generation != cb.generation {
+		return
+	}
+
+	if probe != 0 {
+		if _, ok := cb.probes[probe]; !ok {
+			return
+		}
+		delete(cb.probes, probe)
+	}
+
+	switch outcome {
+	case OutcomeSuccess:
+		cb.onSuccess()
+	case OutcomeFailure:
+		cb.onFailure()
+	case OutcomeIgnored, OutcomeRejected:
+	}
+}
+
+// expireProbes releases probe slots whose lease has run out. Callers must hold mu.
+func (cb *CircuitBreaker) expireProbes(now time.Time) {
+	for probe, deadline := range cb.probes {
+		if !now.Before(deadline) {
+			delete(cb.probes, probe)
+		}
+	}
+}
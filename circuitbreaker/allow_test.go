@@ -0,0 +1,199 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+func TestAllow_ReportsOutcomeFromAnotherGoroutine(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected call to be allowed, got %v", err)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		done(circuitbreaker.OutcomeFailure)
+	}()
+	<-finished
+
+	if cb.State() != circuitbreaker.StateOpen {
+		t.Fatalf("expected StateOpen after reported failure, got %v", cb.State())
+	}
+	if _, err = cb.Allow(); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestAllow_DoneIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(2))
+
+	done, _ := cb.Allow()
+	done(circuitbreaker.OutcomeFailure)
+	done(circuitbreaker.OutcomeFailure)
+
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected a single failure to be counted, got %v", cb.State())
+	}
+}
+
+func TestAllow_IgnoredOutcomeIsNotCounted(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	done, _ := cb.Allow()
+	done(circuitbreaker.OutcomeIgnored)
+
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected StateClosed after ignored outcome, got %v", cb.State())
+	}
+}
+
+func TestAllow_HalfOpenLimitsInFlightProbes(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(20*time.Millisecond),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+	time.Sleep(30 * time.Millisecond)
+
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+
+	// While the single probe is in flight, other calls are rejected.
+	_, err = cb.Allow()
+	var openErr *circuitbreaker.OpenError
+	if !errors.As(err, &openErr) || openErr.State != circuitbreaker.StateHalfOpen {
+		t.Fatalf("expected Half-Open *OpenError, got %v", err)
+	}
+
+	probe(circuitbreaker.OutcomeSuccess)
+
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected StateClosed after successful probe, got %v", cb.State())
+	}
+}
+
+func TestAllow_ForgottenProbeReleasesSlot(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(20*time.Millisecond),
+		circuitbreaker.WithProbeTimeout(30*time.Millisecond),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+	time.Sleep(30 * time.Millisecond)
+
+	forgotten, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if _, err = cb.Allow(); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected probe capacity to be used up, got %v", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected expired probe slot to be released, got %v", err)
+	}
+
+	// The late outcome of the forgotten probe is ignored.
+	forgotten(circuitbreaker.OutcomeFailure)
+	if cb.State() != circuitbreaker.StateHalfOpen {
+		t.Fatalf("expected late outcome to be ignored, got %v", cb.State())
+	}
+
+	probe(circuitbreaker.OutcomeSuccess)
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected StateClosed after successful probe, got %v", cb.State())
+	}
+}
+
+func TestAllow_StaleOutcomeAfterResetIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	done, _ := cb.Allow()
+	cb.Reset()
+	done(circuitbreaker.OutcomeFailure)
+
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected outcome from before Reset to be ignored, got %v", cb.State())
+	}
+}
+
+func TestExecute_WithClassifier(t *testing.T) {
+	t.Parallel()
+
+	errCancelled := errors.New("cancelled by caller")
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithClassifier(func(err error) circuitbreaker.Outcome {
+			if errors.Is(err, errCancelled) {
+				return circuitbreaker.OutcomeIgnored
+			}
+			return circuitbreaker.DefaultClassifier(err)
+		}),
+	)
+
+	if err := cb.Execute(func() error { return errCancelled }); !errors.Is(err, errCancelled) {
+		t.Fatalf("expected errCancelled to be returned, got %v", err)
+	}
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected ignored error not to trip the circuit, got %v", cb.State())
+	}
+}
+
+func TestWithOnCall_ReportsEveryOutcome(t *testing.T) {
+	t.Parallel()
+
+	var outcomes []circuitbreaker.Outcome
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithOnCall(func(outcome circuitbreaker.Outcome, elapsed time.Duration) {
+			if elapsed < 0 {
+				t.Errorf("expected non-negative duration, got %v", elapsed)
+			}
+			outcomes = append(outcomes, outcome)
+		}),
+	)
+
+	_ = cb.Execute(func() error { return nil })
+	_ = cb.Execute(func() error { return errDependency })
+	_ = cb.Execute(func() error { return nil })
+
+	want := []circuitbreaker.Outcome{
+		circuitbreaker.OutcomeSuccess,
+		circuitbreaker.OutcomeFailure,
+		circuitbreaker.OutcomeRejected,
+	}
+	if len(outcomes) != len(want) {
+		t.Fatalf("expected %v, got %v", want, outcomes)
+	}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, outcomes)
+		}
+	}
+}
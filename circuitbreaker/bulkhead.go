@@ -0,0 +1,224 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultMaxConcurrent = 10
+
+// ErrBulkheadFull matches every rejection by a Bulkhead via errors.Is.
+// Rejections are reported as *BulkheadFullError.
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// BulkheadFullError is returned when a Bulkhead rejects a call because all
+// slots are taken and the wait queue is full or the wait timed out.
+// It matches errors.Is(err, ErrBulkheadFull).
+type BulkheadFullError struct {
+	// Name is the bulkhead name set with WithBulkheadName.
+	Name string
+	// MaxConcurrent is the number of concurrent calls the bulkhead allows.
+	MaxConcurrent int
+	// Waited is how long the call waited in the queue; 0 if it was not queued.
+	Waited time.Duration
+}
+
+// Error implements the error interface.
+func (e *BulkheadFullError) Error() string {
+	name := "bulkhead"
+	if e.Name != "" {
+		name = fmt.Sprintf("bulkhead %q", e.Name)
+	}
+
+	if e.Waited > 0 {
+		return fmt.Sprintf("%s is full (%d concurrent calls); gave up after waiting %s", name, e.MaxConcurrent, e.Waited)
+	}
+
+	return fmt.Sprintf("%s is full (%d concurrent calls)", name, e.MaxConcurrent)
+}
+
+// Is reports whether target is ErrBulkheadFull.
+func (e *BulkheadFullError) Is(target error) bool {
+	return target == ErrBulkheadFull //nolint:errorlint // sentinel identity check
+}
+
+// Bulkhead limits the number of concurrent calls to a dependency, so that a
+// slow dependency cannot tie up every goroutine. Calls over the limit wait in
+// an optional bounded queue and are rejected with a *BulkheadFullError when
+// the queue is full or the wait times out. It chains with a CircuitBreaker:
+//
+//	bulkhead.Execute(func() error { return breaker.Execute(call) })
+type Bulkhead struct {
+	mu            sync.Mutex
+	name          string
+	maxConcurrent int
+	queueSize     int
+	maxWait       time.Duration
+	slots         chan struct{}
+	queued        int
+	onRejected    func()
+	nowFunc       func() time.Time // injectable clock for testing
+}
+
+// BulkheadOption configures a Bulkhead.
+type BulkheadOption func(*Bulkhead)
+
+// WithBulkheadName sets the name reported in rejection errors.
+func WithBulkheadName(name string) BulkheadOption {
+	return func(b *Bulkhead) {
+		b.name = name
+	}
+}
+
+// WithMaxConcurrent sets the number of calls allowed to run at once.
+// Default: 10.
+func WithMaxConcurrent(n int) BulkheadOption {
+	return func(b *Bulkhead) {
+		if n > 0 {
+			b.maxConcurrent = n
+		}
+	}
+}
+
+// WithQueueSize sets how many calls may wait for a slot when all are taken.
+// Default: 0 (reject immediately).
+func WithQueueSize(n int) BulkheadOption {
+	return func(b *Bulkhead) {
+		if n >= 0 {
+			b.queueSize = n
+		}
+	}
+}
+
+// WithMaxWait sets how long a queued call waits for a slot before it is
+// rejected. The caller's context deadline applies as well.
+// Default: 0 (wait until the context is done).
+func WithMaxWait(d time.Duration) BulkheadOption {
+	return func(b *Bulkhead) {
+		b.maxWait = d
+	}
+}
+
+// WithOnBulkheadRejected registers a callback invoked for every call the
+// bulkhead rejects. It must not block.
+func WithOnBulkheadRejected(fn func()) BulkheadOption {
+	return func(b *Bulkhead) {
+		b.onRejected = fn
+	}
+}
+
+// NewBulkhead creates a Bulkhead with the given options.
+func NewBulkhead(opts ...BulkheadOption) *Bulkhead {
+	b := &Bulkhead{
+		maxConcurrent: defaultMaxConcurrent,
+		nowFunc:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.slots = make(chan struct{}, b.maxConcurrent)
+
+	return b
+}
+
+// Execute runs fn once a slot is free.
+// Returns a *BulkheadFullError when the call is rejected.
+func (b *Bulkhead) Execute(fn func() error) error {
+	return b.ExecuteContext(context.Background(), fn)
+}
+
+// ExecuteContext runs fn once a slot is free, waiting in the queue no longer
+// than ctx allows. Returns a *BulkheadFullError when the call is rejected, or
+// ctx's error when ctx is done while queued.
+func (b *Bulkhead) ExecuteContext(ctx context.Context, fn func() error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn()
+}
+
+// Acquire takes a slot without running a call. The caller must call release
+// exactly once when the call finishes; further calls are no-ops.
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaser(), nil
+	default:
+	}
+
+	b.mu.Lock()
+	if b.queued >= b.queueSize {
+		b.mu.Unlock()
+		return nil, b.reject(0)
+	}
+	b.queued++
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.queued--
+		b.mu.Unlock()
+	}()
+
+	start := b.nowFunc()
+
+	var timeout <-chan time.Time
+	if b.maxWait > 0 {
+		timer := time.NewTimer(b.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.releaser(), nil
+	case <-timeout:
+		return nil, b.reject(b.nowFunc().Sub(start))
+	case <-ctx.Done():
+		if b.onRejected != nil {
+			b.onRejected()
+		}
+		return nil, ctx.Err() //nolint:wrapcheck // context errors are returned unchanged
+	}
+}
+
+// InFlight returns the number of calls currently holding a slot.
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+// Queued returns the number of calls currently waiting for a slot.
+func (b *Bulkhead) Queued() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queued
+}
+
+// Name returns the bulkhead name set with WithBulkheadName.
+func (b *Bulkhead) Name() string {
+	return b.name
+}
+
+func (b *Bulkhead) releaser() func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { <-b.slots })
+	}
+}
+
+func (b *Bulkhead) reject(waited time.Duration) error {
+	if b.onRejected != nil {
+		b.onRejected()
+	}
+
+	return &BulkheadFullError{Name: b.name, MaxConcurrent: b.maxConcurrent, Waited: waited}
+}
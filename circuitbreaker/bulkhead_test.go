@@ -0,0 +1,169 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+// occupy fills every slot of b with a call blocked until the returned
+// function is called.
+func occupy(t *testing.T, b *circuitbreaker.Bulkhead, n int) func() {
+	t.Helper()
+
+	unblock := make(chan struct{})
+	var started, finished sync.WaitGroup
+
+	for range n {
+		started.Add(1)
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			_ = b.Execute(func() error {
+				started.Done()
+				<-unblock
+				return nil
+			})
+		}()
+	}
+	started.Wait()
+
+	return func() {
+		close(unblock)
+		finished.Wait()
+	}
+}
+
+func TestBulkhead_RejectsOverLimit(t *testing.T) {
+	t.Parallel()
+
+	var rejected atomic.Int64
+	b := circuitbreaker.NewBulkhead(
+		circuitbreaker.WithBulkheadName("reports"),
+		circuitbreaker.WithMaxConcurrent(2),
+		circuitbreaker.WithOnBulkheadRejected(func() { rejected.Add(1) }),
+	)
+
+	release := occupy(t, b, 2)
+	defer release()
+
+	if got := b.InFlight(); got != 2 {
+		t.Fatalf("expected 2 in-flight calls, got %d", got)
+	}
+
+	err := b.Execute(func() error {
+		t.Error("expected fn not to run")
+		return nil
+	})
+
+	var fullErr *circuitbreaker.BulkheadFullError
+	if !errors.As(err, &fullErr) {
+		t.Fatalf("expected *BulkheadFullError, got %v", err)
+	}
+	if !errors.Is(err, circuitbreaker.ErrBulkheadFull) {
+		t.Fatalf("expected error to match ErrBulkheadFull, got %v", err)
+	}
+	if fullErr.Name != "reports" || fullErr.MaxConcurrent != 2 {
+		t.Fatalf("unexpected error fields: %+v", fullErr)
+	}
+	if rejected.Load() != 1 {
+		t.Fatalf("expected 1 rejection hook call, got %d", rejected.Load())
+	}
+}
+
+func TestBulkhead_QueuedCallRunsWhenSlotFrees(t *testing.T) {
+	t.Parallel()
+
+	b := circuitbreaker.NewBulkhead(circuitbreaker.WithMaxConcurrent(1), circuitbreaker.WithQueueSize(1))
+
+	release := occupy(t, b, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- b.Execute(func() error { return nil })
+	}()
+
+	for b.Queued() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The queue is full: a third call is rejected.
+	if err := b.Execute(func() error { return nil }); !errors.Is(err, circuitbreaker.ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull with a full queue, got %v", err)
+	}
+
+	release()
+
+	if err := <-result; err != nil {
+		t.Fatalf("expected queued call to run, got %v", err)
+	}
+}
+
+func TestBulkhead_MaxWait(t *testing.T) {
+	t.Parallel()
+
+	b := circuitbreaker.NewBulkhead(
+		circuitbreaker.WithMaxConcurrent(1),
+		circuitbreaker.WithQueueSize(1),
+		circuitbreaker.WithMaxWait(20*time.Millisecond),
+	)
+
+	release := occupy(t, b, 1)
+	defer release()
+
+	err := b.Execute(func() error { return nil })
+
+	var fullErr *circuitbreaker.BulkheadFullError
+	if !errors.As(err, &fullErr) {
+		t.Fatalf("expected *BulkheadFullError, got %v", err)
+	}
+	if fullErr.Waited < 20*time.Millisecond {
+		t.Fatalf("expected to wait at least 20ms, got %v", fullErr.Waited)
+	}
+}
+
+func TestBulkhead_ContextDeadlineWhileQueued(t *testing.T) {
+	t.Parallel()
+
+	b := circuitbreaker.NewBulkhead(circuitbreaker.WithMaxConcurrent(1), circuitbreaker.WithQueueSize(1))
+
+	release := occupy(t, b, 1)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	err := b.ExecuteContext(ctx, func() error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := b.Queued(); got != 0 {
+		t.Fatalf("expected empty queue after the deadline, got %d", got)
+	}
+}
+
+func TestBulkhead_ChainsWithBreaker(t *testing.T) {
+	t.Parallel()
+
+	var executor circuitbreaker.Executor = circuitbreaker.NewBulkhead(circuitbreaker.WithMaxConcurrent(1))
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	err := executor.Execute(func() error {
+		return cb.Execute(func() error { return errDependency })
+	})
+	if !errors.Is(err, errDependency) {
+		t.Fatalf("expected errDependency, got %v", err)
+	}
+
+	err = executor.Execute(func() error {
+		return cb.Execute(func() error { return nil })
+	})
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+}
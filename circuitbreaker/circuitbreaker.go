@@ -5,6 +5,9 @@ package circuitbreaker
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -16,40 +19,141 @@ const (
 	// StateClosed allows all calls through. Failures are counted;
 	// when the threshold is reached the circuit transitions to Open.
 	StateClosed State = iota
-	// StateOpen rejects all calls immediately with ErrCircuitOpen.
+	// StateOpen rejects all calls immediately with an *OpenError.
 	// After the configured timeout the circuit transitions to Half-Open.
 	StateOpen
 	// StateHalfOpen allows a limited number of probe calls through.
 	// On success the circuit resets to Closed; on failure it returns to Open.
 	StateHalfOpen
+	// StateForcedOpen rejects all calls until ClearOverride or Reset is called.
+	// Automatic transitions are suspended. Set by ForceOpen.
+	StateForcedOpen
+	// StateForcedClosed allows all calls until ClearOverride or Reset is called.
+	// Outcomes are still recorded in Counts but do not affect the state;
+	// automatic transitions are suspended. Set by ForceClosed.
+	StateForcedClosed
 )
 
 const (
-	defaultThreshold   = 5
-	defaultTimeout     = 30 * time.Second
-	defaultHalfOpenMax = 1
+	defaultThreshold    = 5
+	defaultTimeout      = 30 * time.Second
+	defaultHalfOpenMax  = 1
+	defaultMultiplier   = 2
+	defaultProbeTimeout = 30 * time.Second
+	defaultSyncInterval = time.Second
 )
 
-// ErrCircuitOpen is returned when a call is rejected because the circuit is open.
+// String returns the lower-case name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	case StateForcedOpen:
+		return "forced-open"
+	case StateForcedClosed:
+		return "forced-closed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// ErrCircuitOpen matches every rejection by the breaker via errors.Is.
+// Rejections are reported as *OpenError.
 var ErrCircuitOpen = errors.New("circuit breaker is open")
 
+// OpenError is returned when a call is rejected, either because the circuit
+// is Open or forced open, or because all Half-Open probe slots are taken.
+// It matches errors.Is(err, ErrCircuitOpen).
+type OpenError struct {
+	// Name is the breaker name set with WithName.
+	Name string
+	// State is the breaker state at the time of the rejection.
+	State State
+	// RetryAfter is the time until the breaker allows the next probe.
+	// It is 0 when the rejection came from a Half-Open or forced-open breaker
+	// or from an AdaptiveThrottle.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *OpenError) Error() string {
+	name := "circuit breaker"
+	if e.Name != "" {
+		name = fmt.Sprintf("circuit breaker %q", e.Name)
+	}
+
+	switch e.State {
+	case StateHalfOpen:
+		return name + " is half-open and has no probe slots left"
+	case StateForcedOpen:
+		return name + " is forced open"
+	case StateClosed, StateOpen, StateForcedClosed:
+	}
+
+	if e.RetryAfter <= 0 {
+		return name + " is open"
+	}
+
+	return fmt.Sprintf("%s is open; next probe in %s", name, e.RetryAfter)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen //nolint:errorlint // sentinel identity check
+}
+
 // CircuitBreaker guards calls to an unreliable dependency.
 type CircuitBreaker struct {
 	mu            sync.Mutex
+	name          string
 	state         State
 	failures      int
 	successes     int // half-open probe successes
 	lastFailure   time.Time
+	changedAt     time.Time
 	threshold     int
-	timeout       time.Duration
+	timeout       time.Duration // base open duration
+	backoff       time.Duration // open duration after backoff, before jitter
+	openTimeout   time.Duration // open duration in effect, including backoff and jitter
+	maxTimeout    time.Duration // cap for the backed-off open duration; 0 disables backoff
+	multiplier    float64
+	jitter        float64
 	halfOpenMax   int
+	probeTimeout  time.Duration
+	probes        map[uint64]time.Time // in-flight Half-Open probes and their lease deadlines
+	nextProbe     uint64
+	generation    uint64 // incremented on every state change; stale done calls are ignored
+	classify      func(err error) Outcome
+	counts        Counts
+	subscribers   map[uint64]chan Event
+	subscriberSeq uint64
+	store         StateStore
+	syncInterval  time.Duration
+	lastSync      time.Time
+	syncing       bool         // a sync is in flight outside mu
+	shared        SharedState  // shared state seen at the last sync
+	unsynced      Counts       // outcomes not yet added to the store
+	pending       *SharedState // trip or recovery to publish once mu is released
+	onStoreError  func(err error)
 	onStateChange func(from, to State)
+	onCall        func(outcome Outcome, elapsed time.Duration)
 	nowFunc       func() time.Time // injectable clock for testing
 }
 
 // Option configures the circuit breaker.
 type Option func(*CircuitBreaker)
 
+// WithName sets the breaker name reported in errors and by Name.
+func WithName(name string) Option {
+	return func(cb *CircuitBreaker) {
+		cb.name = name
+	}
+}
+
 // WithThreshold sets the consecutive failure count that trips the circuit to Open.
 // Default: 5.
 func WithThreshold(n int) Option {
@@ -61,6 +165,7 @@ func WithThreshold(n int) Option {
 }
 
 // WithTimeout sets the duration the circuit stays Open before transitioning to Half-Open.
+// This is also the starting point for WithBackoff.
 // Default: 30s.
 func WithTimeout(d time.Duration) Option {
 	return func(cb *CircuitBreaker) {
@@ -68,6 +173,32 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithBackoff makes the open duration grow after every failed Half-Open probe.
+// Each failed probe multiplies the open duration by multiplier, up to maxTimeout.
+// The open duration returns to the WithTimeout value once the circuit recovers to Closed.
+// A multiplier below 1 falls back to 2, and a maxTimeout of 0 means no cap.
+// Default: disabled.
+func WithBackoff(multiplier float64, maxTimeout time.Duration) Option {
+	return func(cb *CircuitBreaker) {
+		if multiplier < 1 {
+			multiplier = defaultMultiplier
+		}
+		cb.multiplier = multiplier
+		cb.maxTimeout = maxTimeout
+	}
+}
+
+// WithTimeoutJitter randomizes every open duration by ±fraction (e.g. 0.1 for ±10%)
+// so that breakers tripped together do not probe in lockstep.
+// Default: 0 (no jitter).
+func WithTimeoutJitter(fraction float64) Option {
+	return func(cb *CircuitBreaker) {
+		if fraction >= 0 && fraction <= 1 {
+			cb.jitter = fraction
+		}
+	}
+}
+
 // WithHalfOpenMax sets the maximum number of probe calls allowed in Half-Open state.
 // If all probes succeed, the circuit resets to Closed.
 // Default: 1.
@@ -79,80 +210,158 @@ func WithHalfOpenMax(n int) Option {
 	}
 }
 
+// WithProbeTimeout sets how long a Half-Open probe slot reserved by Allow stays
+// taken without its done function being called. After that the slot is
+// released and the late outcome is ignored.
+// Default: 30s.
+func WithProbeTimeout(d time.Duration) Option {
+	return func(cb *CircuitBreaker) {
+		if d > 0 {
+			cb.probeTimeout = d
+		}
+	}
+}
+
+// WithClassifier sets how Execute maps the error returned by fn to an Outcome,
+// e.g. to ignore context cancellations. Default: DefaultClassifier.
+func WithClassifier(fn func(err error) Outcome) Option {
+	return func(cb *CircuitBreaker) {
+		cb.classify = fn
+	}
+}
+
 // WithOnStateChange registers a callback invoked on every state transition.
+// It runs while the breaker is locked and must not call back into it.
 func WithOnStateChange(fn func(from, to State)) Option {
 	return func(cb *CircuitBreaker) {
 		cb.onStateChange = fn
 	}
 }
 
+// WithOnCall registers a callback invoked once per call with its outcome and
+// duration. Rejected calls are reported as OutcomeRejected with zero duration.
+// It runs on the caller's goroutine and must not block.
+func WithOnCall(fn func(outcome Outcome, elapsed time.Duration)) Option {
+	return func(cb *CircuitBreaker) {
+		cb.onCall = fn
+	}
+}
+
 // New creates a CircuitBreaker with the given options.
 func New(opts ...Option) *CircuitBreaker {
 	cb := &CircuitBreaker{
-		state:       StateClosed,
-		threshold:   defaultThreshold,
-		timeout:     defaultTimeout,
-		halfOpenMax: defaultHalfOpenMax,
-		nowFunc:     time.Now,
+		state:        StateClosed,
+		threshold:    defaultThreshold,
+		timeout:      defaultTimeout,
+		halfOpenMax:  defaultHalfOpenMax,
+		probeTimeout: defaultProbeTimeout,
+		probes:       make(map[uint64]time.Time),
+		subscribers:  make(map[uint64]chan Event),
+		classify:     DefaultClassifier,
+		syncInterval: defaultSyncInterval,
+		multiplier:   1,
+		nowFunc:      time.Now,
 	}
 
 	for _, opt := range opts {
 		opt(cb)
 	}
 
+	cb.backoff = cb.timeout
+	cb.openTimeout = cb.timeout
+
+	// Shared state is keyed by name, so unnamed breakers stay local.
+	if cb.name == "" && cb.store != nil {
+		cb.store = nil
+		cb.storeError(ErrUnnamedBreaker)
+	}
+
 	return cb
 }
 
 // Execute runs fn if the circuit allows it.
-// Returns ErrCircuitOpen when the breaker is open and the timeout has not elapsed.
+// Returns an *OpenError when the breaker is open and the timeout has not elapsed.
 func (cb *CircuitBreaker) Execute(fn func() error) error {
-	cb.mu.Lock()
-
-	// Evaluate current state, possibly transitioning Open → Half-Open.
-	switch cb.state {
-	case StateOpen:
-		if cb.nowFunc().Sub(cb.lastFailure) >= cb.timeout {
-			cb.transitionTo(StateHalfOpen)
-		} else {
-			cb.mu.Unlock()
-			return ErrCircuitOpen
-		}
-	case StateHalfOpen:
-		// Already in half-open — allow if we haven't exceeded max probes.
-		// Additional calls beyond halfOpenMax are rejected.
-		if cb.successes >= cb.halfOpenMax {
-			cb.mu.Unlock()
-			return ErrCircuitOpen
-		}
-	case StateClosed:
-		// Allow through
+	done, err := cb.Allow()
+	if err != nil {
+		return err
 	}
 
-	cb.mu.Unlock()
-
 	// Execute the function outside the lock.
-	err := fn()
+	err = fn()
+	done(cb.classify(err))
+
+	return err
+}
 
+// State returns the current circuit breaker state.
+func (cb *CircuitBreaker) State() State {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	return cb.state
+}
 
-	if err != nil {
-		cb.onFailure()
-	} else {
-		cb.onSuccess()
+// Timeout returns the open duration currently in effect. It equals the
+// WithTimeout value unless backoff or jitter has adjusted it.
+func (cb *CircuitBreaker) Timeout() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.openTimeout
+}
+
+// Name returns the breaker name set with WithName.
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// openError describes a rejection in the current state. Callers must hold mu.
+func (cb *CircuitBreaker) openError() *OpenError {
+	err := &OpenError{Name: cb.name, State: cb.state}
+	if cb.state == StateOpen {
+		err.RetryAfter = max(cb.openTimeout-cb.nowFunc().Sub(cb.lastFailure), 0)
 	}
 
 	return err
 }
 
-// State returns the current circuit breaker state.
-func (cb *CircuitBreaker) State() State {
+// ForceOpen rejects every call until ClearOverride or Reset is called,
+// e.g. to shed load from a broken dependency during an incident.
+func (cb *CircuitBreaker) ForceOpen() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
-	return cb.state
+
+	if cb.state != StateForcedOpen {
+		cb.transitionTo(StateForcedOpen)
+	}
 }
 
-// Reset forces the breaker back to Closed with zero counters.
+// ForceClosed allows every call until ClearOverride or Reset is called,
+// e.g. to bypass a misbehaving breaker. Outcomes are still recorded in
+// Counts meanwhile, but do not affect the state.
+func (cb *CircuitBreaker) ForceClosed() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != StateForcedClosed {
+		cb.transitionTo(StateForcedClosed)
+	}
+}
+
+// ClearOverride ends ForceOpen or ForceClosed and resumes automatic
+// operation from Closed. It does nothing if no override is active.
+func (cb *CircuitBreaker) ClearOverride() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateForcedOpen || cb.state == StateForcedClosed {
+		cb.backoff = cb.timeout
+		cb.openTimeout = cb.timeout
+		cb.transitionTo(StateClosed)
+	}
+}
+
+// Reset forces the breaker back to Closed with zero counters and Counts.
+// It also clears any override.
 func (cb *CircuitBreaker) Reset() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
@@ -161,9 +370,17 @@ func (cb *CircuitBreaker) Reset() {
 	cb.state = StateClosed
 	cb.failures = 0
 	cb.successes = 0
+	cb.backoff = cb.timeout
+	cb.openTimeout = cb.timeout
+	cb.counts = Counts{}
+	cb.generation++
+	clear(cb.probes)
 
-	if from != StateClosed && cb.onStateChange != nil {
-		cb.onStateChange(from, StateClosed)
+	if from != StateClosed {
+		cb.emit(EventStateChanged, from, StateClosed)
+		if cb.onStateChange != nil {
+			cb.onStateChange(from, StateClosed)
+		}
 	}
 }
 
@@ -174,10 +391,13 @@ func (cb *CircuitBreaker) onSuccess() {
 	case StateHalfOpen:
 		cb.successes++
 		if cb.successes >= cb.halfOpenMax {
+			cb.backoff = cb.timeout
+			cb.openTimeout = cb.timeout
 			cb.transitionTo(StateClosed)
+			cb.stagePublish()
 		}
-	case StateOpen:
-		// Should not happen
+	case StateOpen, StateForcedOpen, StateForcedClosed:
+		// No effect on the state
 	}
 }
 
@@ -187,23 +407,58 @@ func (cb *CircuitBreaker) onFailure() {
 		cb.failures++
 		if cb.failures >= cb.threshold {
 			cb.lastFailure = cb.nowFunc()
+			cb.openTimeout = cb.jittered(cb.backoff)
 			cb.transitionTo(StateOpen)
+			cb.stagePublish()
 		}
 	case StateHalfOpen:
 		cb.lastFailure = cb.nowFunc()
+		cb.backoff = cb.grow(cb.backoff)
+		cb.openTimeout = cb.jittered(cb.backoff)
 		cb.transitionTo(StateOpen)
-	case StateOpen:
-		// Should not happen
+		cb.stagePublish()
+	case StateOpen, StateForcedOpen, StateForcedClosed:
+		// No effect on the state
 	}
 }
 
 func (cb *CircuitBreaker) transitionTo(to State) {
 	from := cb.state
 	cb.state = to
+	cb.changedAt = cb.nowFunc()
 	cb.failures = 0
 	cb.successes = 0
+	cb.generation++
+	clear(cb.probes)
 
+	cb.emit(EventStateChanged, from, to)
 	if cb.onStateChange != nil {
 		cb.onStateChange(from, to)
 	}
 }
+
+// grow applies one backoff step to timeout, capped by maxTimeout.
+func (cb *CircuitBreaker) grow(timeout time.Duration) time.Duration {
+	next := time.Duration(math.MaxInt64)
+	if scaled := float64(timeout) * cb.multiplier; scaled < math.MaxInt64 {
+		next = time.Duration(scaled)
+	}
+
+	if cb.maxTimeout > 0 && next > cb.maxTimeout {
+		next = max(cb.maxTimeout, cb.timeout)
+	}
+
+	return next
+}
+
+// jittered randomizes timeout by ±jitter.
+func (cb *CircuitBreaker) jittered(timeout time.Duration) time.Duration {
+	if cb.jitter <= 0 {
+		return timeout
+	}
+
+	delta := float64(timeout) * cb.jitter
+	timeout += time.Duration((rand.Float64()*2 - 1) * delta) //nolint:gosec // jitter does not need crypto rand
+
+	return max(timeout, 0)
+}
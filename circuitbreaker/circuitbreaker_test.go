@@ -268,3 +268,224 @@ func TestCircuitBreaker_DefaultOptions(t *testing.T) {
 		t.Fatalf("expected StateOpen at threshold 5, got %v", cb.State())
 	}
 }
+
+func TestCircuitBreaker_BackoffGrowsOpenTimeout(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(20*time.Second),
+		circuitbreaker.WithBackoff(2, 50*time.Second),
+	)
+
+	now := time.Unix(0, 0)
+	circuitbreaker.SetNowFunc(cb, func() time.Time { return now })
+
+	// Trip the circuit: the first open period uses the base timeout.
+	_ = cb.Execute(func() error { return errDependency })
+	if got := cb.Timeout(); got != 20*time.Second {
+		t.Fatalf("expected 20s timeout after trip, got %v", got)
+	}
+
+	// Failed probe doubles the timeout.
+	now = now.Add(20 * time.Second)
+	_ = cb.Execute(func() error { return errDependency })
+	if got := cb.Timeout(); got != 40*time.Second {
+		t.Fatalf("expected 40s timeout after failed probe, got %v", got)
+	}
+
+	// Probing before the grown timeout elapses is rejected.
+	now = now.Add(40*time.Second - time.Nanosecond)
+	if err := cb.Execute(func() error { return nil }); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen before backed-off timeout, got %v", err)
+	}
+
+	// Second failed probe is capped at the maximum.
+	now = now.Add(time.Nanosecond)
+	_ = cb.Execute(func() error { return errDependency })
+	if got := cb.Timeout(); got != 50*time.Second {
+		t.Fatalf("expected timeout capped at 50s, got %v", got)
+	}
+
+	// Recovery to Closed resets the timeout.
+	now = now.Add(50 * time.Second)
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected StateClosed after recovery, got %v", cb.State())
+	}
+	if got := cb.Timeout(); got != 20*time.Second {
+		t.Fatalf("expected timeout reset to 20s, got %v", got)
+	}
+}
+
+func TestCircuitBreaker_BackoffWithoutCap(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Second),
+		circuitbreaker.WithBackoff(3, 0),
+	)
+
+	now := time.Unix(0, 0)
+	circuitbreaker.SetNowFunc(cb, func() time.Time { return now })
+
+	_ = cb.Execute(func() error { return errDependency })
+	for _, want := range []time.Duration{3 * time.Second, 9 * time.Second, 27 * time.Second} {
+		now = now.Add(cb.Timeout())
+		_ = cb.Execute(func() error { return errDependency })
+		if got := cb.Timeout(); got != want {
+			t.Fatalf("expected an uncapped %v timeout, got %v", want, got)
+		}
+	}
+}
+
+func TestCircuitBreaker_TimeoutJitter(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Second),
+		circuitbreaker.WithTimeoutJitter(0.1),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+
+	got := cb.Timeout()
+	if got < 900*time.Millisecond || got > 1100*time.Millisecond {
+		t.Fatalf("expected jittered timeout within ±10%% of 1s, got %v", got)
+	}
+}
+
+func TestCircuitBreaker_OpenError(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Minute),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+	err := cb.Execute(func() error { return nil })
+
+	var openErr *circuitbreaker.OpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected *OpenError, got %T", err)
+	}
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatal("expected *OpenError to match ErrCircuitOpen")
+	}
+	if openErr.Name != "payments" || cb.Name() != "payments" {
+		t.Fatalf("expected name payments, got %q", openErr.Name)
+	}
+	if openErr.State != circuitbreaker.StateOpen {
+		t.Fatalf("expected StateOpen, got %v", openErr.State)
+	}
+	if openErr.RetryAfter <= 0 || openErr.RetryAfter > time.Minute {
+		t.Fatalf("expected RetryAfter within (0, 1m], got %v", openErr.RetryAfter)
+	}
+}
+
+func TestState_String(t *testing.T) {
+	t.Parallel()
+
+	for state, want := range map[circuitbreaker.State]string{
+		circuitbreaker.StateClosed:       "closed",
+		circuitbreaker.StateOpen:         "open",
+		circuitbreaker.StateHalfOpen:     "half-open",
+		circuitbreaker.StateForcedOpen:   "forced-open",
+		circuitbreaker.StateForcedClosed: "forced-closed",
+	} {
+		if got := state.String(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestCircuitBreaker_ForceOpen(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithTimeout(time.Millisecond))
+
+	cb.ForceOpen()
+	time.Sleep(5 * time.Millisecond)
+
+	// The open timeout does not apply to a forced-open breaker.
+	err := cb.Execute(func() error { return nil })
+	var openErr *circuitbreaker.OpenError
+	if !errors.As(err, &openErr) || openErr.State != circuitbreaker.StateForcedOpen {
+		t.Fatalf("expected forced-open *OpenError, got %v", err)
+	}
+	if cb.State() != circuitbreaker.StateForcedOpen {
+		t.Fatalf("expected StateForcedOpen, got %v", cb.State())
+	}
+
+	cb.ClearOverride()
+
+	if err = cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("expected calls to be allowed after ClearOverride, got %v", err)
+	}
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Fatalf("expected StateClosed after ClearOverride, got %v", cb.State())
+	}
+}
+
+func TestCircuitBreaker_ForceClosed(t *testing.T) {
+	t.Parallel()
+
+	var transitions []circuitbreaker.State
+	cb := circuitbreaker.New(
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithOnStateChange(func(_, to circuitbreaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+	cb.ForceClosed()
+
+	// Failures neither trip a forced-closed breaker nor get rejected.
+	for range 3 {
+		if err := cb.Execute(func() error { return errDependency }); !errors.Is(err, errDependency) {
+			t.Fatalf("expected errDependency, got %v", err)
+		}
+	}
+	if cb.State() != circuitbreaker.StateForcedClosed {
+		t.Fatalf("expected StateForcedClosed, got %v", cb.State())
+	}
+	if counts := cb.Counts(); counts.TotalFailures != 4 {
+		t.Fatalf("expected failures to be recorded in Counts, got %+v", counts)
+	}
+
+	cb.Reset()
+
+	want := []circuitbreaker.State{
+		circuitbreaker.StateOpen,
+		circuitbreaker.StateForcedClosed,
+		circuitbreaker.StateClosed,
+	}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("expected transitions %v, got %v", want, transitions)
+		}
+	}
+}
+
+func TestCircuitBreaker_ClearOverrideWithoutOverride(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	_ = cb.Execute(func() error { return errDependency })
+	cb.ClearOverride()
+
+	if cb.State() != circuitbreaker.StateOpen {
+		t.Fatalf("expected ClearOverride to leave automatic state alone, got %v", cb.State())
+	}
+}
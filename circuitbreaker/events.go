@@ -0,0 +1,128 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// Counts is a snapshot of a breaker's call statistics since it was created
+// or last Reset.
+type Counts struct {
+	// Requests is the number of calls the breaker admitted.
+	Requests uint64 `json:"requests"`
+	// TotalSuccesses is the number of calls reported as successful.
+	TotalSuccesses uint64 `json:"total_successes"`
+	// TotalFailures is the number of calls reported as failed.
+	TotalFailures uint64 `json:"total_failures"`
+	// ConsecutiveSuccesses is the number of successes since the last failure.
+	ConsecutiveSuccesses uint64 `json:"consecutive_successes"`
+	// ConsecutiveFailures is the number of failures since the last success.
+	ConsecutiveFailures uint64 `json:"consecutive_failures"`
+	// Rejections is the number of calls the breaker rejected.
+	Rejections uint64 `json:"rejections"`
+	// LastFailure is when the last failure was reported; zero if none.
+	LastFailure time.Time `json:"last_failure"`
+}
+
+// EventType identifies the kind of an Event.
+type EventType int
+
+const (
+	// EventStateChanged is emitted on every state transition.
+	EventStateChanged EventType = iota
+	// EventCallRejected is emitted when a call is rejected.
+	EventCallRejected
+	// EventProbeStarted is emitted when a Half-Open probe is admitted.
+	EventProbeStarted
+)
+
+// String returns the lower-case name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventStateChanged:
+		return "state-changed"
+	case EventCallRejected:
+		return "call-rejected"
+	case EventProbeStarted:
+		return "probe-started"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event describes something that happened inside a breaker.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Name is the breaker name set with WithName.
+	Name string
+	// From and To are the states of an EventStateChanged.
+	// For other events both hold the current state.
+	From State
+	To   State
+}
+
+// Counts returns a snapshot of the breaker's call statistics.
+func (cb *CircuitBreaker) Counts() Counts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.counts
+}
+
+// Subscribe returns a channel that receives the breaker's events and a
+// function that ends the subscription and closes the channel. Events are
+// delivered without blocking the breaker: when the channel buffer is full,
+// further events are dropped until the subscriber catches up.
+func (cb *CircuitBreaker) Subscribe(buffer int) (events <-chan Event, unsubscribe func()) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	ch := make(chan Event, max(buffer, 0))
+	cb.subscriberSeq++
+	id := cb.subscriberSeq
+	cb.subscribers[id] = ch
+
+	return ch, func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+
+		if sub, ok := cb.subscribers[id]; ok {
+			delete(cb.subscribers, id)
+			close(sub)
+		}
+	}
+}
+
+// emit delivers an event to every subscriber without blocking.
+// Callers must hold mu.
+func (cb *CircuitBreaker) emit(eventType EventType, from, to State) {
+	if len(cb.subscribers) == 0 {
+		return
+	}
+
+	event := Event{Type: eventType, Time: cb.nowFunc(), Name: cb.name, From: from, To: to}
+	for _, sub := range cb.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
+
+// countOutcome updates counts with a reported outcome. Callers must hold mu.
+func (cb *CircuitBreaker) countOutcome(outcome Outcome) {
+	switch outcome {
+	case OutcomeSuccess:
+		cb.counts.TotalSuccesses++
+		cb.unsynced.TotalSuccesses++
+		cb.counts.ConsecutiveSuccesses++
+		cb.counts.ConsecutiveFailures = 0
+	case OutcomeFailure:
+		cb.counts.TotalFailures++
+		cb.unsynced.TotalFailures++
+		cb.counts.ConsecutiveFailures++
+		cb.counts.ConsecutiveSuccesses = 0
+		cb.counts.LastFailure = cb.nowFunc()
+	case OutcomeIgnored, OutcomeRejected:
+	}
+}
@@ -0,0 +1,114 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+func TestCounts(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(3))
+
+	_ = cb.Execute(func() error { return nil })
+	_ = cb.Execute(func() error { return nil })
+	before := time.Now()
+	_ = cb.Execute(func() error { return errDependency })
+
+	counts := cb.Counts()
+	if counts.Requests != 3 || counts.TotalSuccesses != 2 || counts.TotalFailures != 1 {
+		t.Fatalf("unexpected totals: %+v", counts)
+	}
+	if counts.ConsecutiveFailures != 1 || counts.ConsecutiveSuccesses != 0 {
+		t.Fatalf("unexpected consecutive counts: %+v", counts)
+	}
+	if counts.LastFailure.Before(before) {
+		t.Fatalf("expected LastFailure after %v, got %v", before, counts.LastFailure)
+	}
+
+	_ = cb.Execute(func() error { return errDependency })
+	_ = cb.Execute(func() error { return errDependency })
+	_ = cb.Execute(func() error { return nil })
+
+	counts = cb.Counts()
+	if counts.Rejections != 1 || counts.Requests != 5 || counts.ConsecutiveFailures != 3 {
+		t.Fatalf("unexpected counts after trip: %+v", counts)
+	}
+
+	cb.Reset()
+	if counts = cb.Counts(); counts != (circuitbreaker.Counts{}) {
+		t.Fatalf("expected zero counts after Reset, got %+v", counts)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(20*time.Millisecond),
+	)
+	events, unsubscribe := cb.Subscribe(16)
+
+	_ = cb.Execute(func() error { return errDependency }) // Closed → Open
+	_ = cb.Execute(func() error { return nil })           // rejected
+	time.Sleep(30 * time.Millisecond)
+	_ = cb.Execute(func() error { return nil }) // Open → Half-Open, probe, Half-Open → Closed
+
+	unsubscribe()
+	unsubscribe() // safe to call twice
+
+	want := []struct {
+		eventType circuitbreaker.EventType
+		from, to  circuitbreaker.State
+	}{
+		{circuitbreaker.EventStateChanged, circuitbreaker.StateClosed, circuitbreaker.StateOpen},
+		{circuitbreaker.EventCallRejected, circuitbreaker.StateOpen, circuitbreaker.StateOpen},
+		{circuitbreaker.EventStateChanged, circuitbreaker.StateOpen, circuitbreaker.StateHalfOpen},
+		{circuitbreaker.EventProbeStarted, circuitbreaker.StateHalfOpen, circuitbreaker.StateHalfOpen},
+		{circuitbreaker.EventStateChanged, circuitbreaker.StateHalfOpen, circuitbreaker.StateClosed},
+	}
+
+	var got []circuitbreaker.Event
+	for event := range events {
+		got = append(got, event)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(got), got)
+	}
+	for i, event := range got {
+		if event.Type != want[i].eventType || event.From != want[i].from || event.To != want[i].to {
+			t.Fatalf("event %d: expected %v %v→%v, got %v %v→%v",
+				i, want[i].eventType, want[i].from, want[i].to, event.Type, event.From, event.To)
+		}
+		if event.Name != "payments" || event.Time.IsZero() {
+			t.Fatalf("event %d: expected name and timestamp, got %+v", i, event)
+		}
+	}
+}
+
+func TestSubscribe_DropsWhenFull(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+	events, unsubscribe := cb.Subscribe(1)
+	defer unsubscribe()
+
+	_ = cb.Execute(func() error { return errDependency })
+
+	// A slow subscriber never blocks the breaker.
+	for range 10 {
+		_ = cb.Execute(func() error { return nil })
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected buffered channel to hold 1 event, got %d", len(events))
+	}
+	if event := <-events; event.Type != circuitbreaker.EventStateChanged {
+		t.Fatalf("expected first event to be kept, got %v", event.Type)
+	}
+}
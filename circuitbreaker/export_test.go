@@ -0,0 +1,10 @@
+package circuitbreaker
+
+import "time"
+
+// SetNowFunc replaces the clock of cb, so tests can control time without sleeping.
+func SetNowFunc(cb *CircuitBreaker, now func() time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.nowFunc = now
+}
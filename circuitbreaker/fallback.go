@@ -0,0 +1,41 @@
+package circuitbreaker
+
+// ExecuteWithFallback runs fn through the breaker. If the call is rejected
+// or fn fails, fallback is called with that error and its result is returned
+// instead. Rejections can be told apart with errors.Is(err, ErrCircuitOpen).
+func (cb *CircuitBreaker) ExecuteWithFallback(fn func() error, fallback func(err error) error) error {
+	if err := cb.Execute(fn); err != nil {
+		return fallback(err)
+	}
+
+	return nil
+}
+
+// ExecuteValue runs fn through the breaker and returns its value.
+// On rejection it returns the zero value and an *OpenError.
+//
+//nolint:ireturn // generic type parameter T
+func ExecuteValue[T any](cb *CircuitBreaker, fn func() (T, error)) (T, error) {
+	var value T
+	err := cb.Execute(func() error {
+		var fnErr error
+		value, fnErr = fn()
+		return fnErr
+	})
+
+	return value, err
+}
+
+// ExecuteValueWithFallback runs fn through the breaker and returns its value.
+// If the call is rejected or fn fails, fallback is called with that error and
+// its result is returned instead, e.g. a cached or default value.
+//
+//nolint:ireturn // generic type parameter T
+func ExecuteValueWithFallback[T any](cb *CircuitBreaker, fn func() (T, error), fallback func(err error) (T, error)) (T, error) {
+	value, err := ExecuteValue(cb, fn)
+	if err != nil {
+		return fallback(err)
+	}
+
+	return value, nil
+}
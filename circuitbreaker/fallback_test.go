@@ -0,0 +1,80 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+func TestExecuteWithFallback(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	var fallbackErrs []error
+	fallback := func(err error) error {
+		fallbackErrs = append(fallbackErrs, err)
+		return nil
+	}
+
+	// Failure of fn is handed to the fallback.
+	if err := cb.ExecuteWithFallback(func() error { return errDependency }, fallback); err != nil {
+		t.Fatalf("expected fallback to absorb the failure, got %v", err)
+	}
+
+	// Rejection is handed to the fallback as well.
+	if err := cb.ExecuteWithFallback(func() error { return nil }, fallback); err != nil {
+		t.Fatalf("expected fallback to absorb the rejection, got %v", err)
+	}
+
+	if len(fallbackErrs) != 2 {
+		t.Fatalf("expected fallback to run twice, got %d", len(fallbackErrs))
+	}
+	if !errors.Is(fallbackErrs[0], errDependency) {
+		t.Fatalf("expected errDependency, got %v", fallbackErrs[0])
+	}
+	if !errors.Is(fallbackErrs[1], circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", fallbackErrs[1])
+	}
+}
+
+func TestExecuteValue(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+
+	value, err := circuitbreaker.ExecuteValue(cb, func() (int, error) { return 42, nil })
+	if err != nil || value != 42 {
+		t.Fatalf("expected 42, got %d, %v", value, err)
+	}
+
+	_, _ = circuitbreaker.ExecuteValue(cb, func() (int, error) { return 0, errDependency })
+
+	value, err = circuitbreaker.ExecuteValue(cb, func() (int, error) { return 42, nil })
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) || value != 0 {
+		t.Fatalf("expected zero value and ErrCircuitOpen, got %d, %v", value, err)
+	}
+}
+
+func TestExecuteValueWithFallback(t *testing.T) {
+	t.Parallel()
+
+	cb := circuitbreaker.New(circuitbreaker.WithThreshold(1))
+	cached := func(error) (string, error) { return "cached", nil }
+
+	value, err := circuitbreaker.ExecuteValueWithFallback(cb, func() (string, error) { return "fresh", nil }, cached)
+	if err != nil || value != "fresh" {
+		t.Fatalf("expected fresh value, got %q, %v", value, err)
+	}
+
+	value, err = circuitbreaker.ExecuteValueWithFallback(cb, func() (string, error) { return "", errDependency }, cached)
+	if err != nil || value != "cached" {
+		t.Fatalf("expected cached value after failure, got %q, %v", value, err)
+	}
+
+	value, err = circuitbreaker.ExecuteValueWithFallback(cb, func() (string, error) { return "fresh", nil }, cached)
+	if err != nil || value != "cached" {
+		t.Fatalf("expected cached value while open, got %q, %v", value, err)
+	}
+}
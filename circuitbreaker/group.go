@@ -0,0 +1,215 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Group lazily creates and manages one CircuitBreaker per key, for example
+// per downstream host or per tenant. All breakers share an option template
+// that can be overridden per key.
+type Group[K comparable] struct {
+	mu            sync.Mutex
+	breakers      map[K]*groupEntry
+	options       []Option
+	overrides     map[K][]Option
+	idleTTL       time.Duration
+	lastSweep     time.Time
+	onStateChange func(key K, from, to State)
+	onCall        func(key K, outcome Outcome, elapsed time.Duration)
+	nowFunc       func() time.Time // injectable clock for testing
+}
+
+type groupEntry struct {
+	breaker  *CircuitBreaker
+	lastUsed time.Time
+}
+
+// GroupOption configures a Group.
+type GroupOption[K comparable] func(*Group[K])
+
+// WithGroupOptions sets the option template applied to every breaker created by the group.
+func WithGroupOptions[K comparable](opts ...Option) GroupOption[K] {
+	return func(g *Group[K]) {
+		g.options = append(g.options, opts...)
+	}
+}
+
+// WithKeyOptions sets options for a single key. They are applied after the
+// group template, so they override it.
+func WithKeyOptions[K comparable](key K, opts ...Option) GroupOption[K] {
+	return func(g *Group[K]) {
+		g.overrides[key] = append(g.overrides[key], opts...)
+	}
+}
+
+// WithIdleTTL evicts breakers that have not been used for d. Eviction drops
+// the breaker's state, so d should comfortably exceed the open timeout.
+// Default: 0 (never evict).
+func WithIdleTTL[K comparable](d time.Duration) GroupOption[K] {
+	return func(g *Group[K]) {
+		g.idleTTL = d
+	}
+}
+
+// WithGroupOnStateChange registers a callback invoked on every state transition
+// of any breaker in the group. It runs in addition to any WithOnStateChange
+// callback from the option template. It runs while the transitioning breaker
+// is locked, so it must not call back into the group or its breakers; hand
+// the transition to another goroutine to do so.
+func WithGroupOnStateChange[K comparable](fn func(key K, from, to State)) GroupOption[K] {
+	return func(g *Group[K]) {
+		g.onStateChange = fn
+	}
+}
+
+// WithGroupOnCall registers a callback invoked once per call through any
+// breaker in the group, with the breaker's key. It runs in addition to any
+// WithOnCall callback from the option template, on the caller's goroutine,
+// and must not block.
+func WithGroupOnCall[K comparable](fn func(key K, outcome Outcome, elapsed time.Duration)) GroupOption[K] {
+	return func(g *Group[K]) {
+		g.onCall = fn
+	}
+}
+
+// NewGroup creates a Group with the given options.
+func NewGroup[K comparable](opts ...GroupOption[K]) *Group[K] {
+	group := &Group[K]{
+		breakers:  make(map[K]*groupEntry),
+		overrides: make(map[K][]Option),
+		nowFunc:   time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(group)
+	}
+
+	return group
+}
+
+// Get returns the breaker for key, creating it on first use.
+func (g *Group[K]) Get(key K) *CircuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.nowFunc()
+	g.sweep(now)
+
+	entry, ok := g.breakers[key]
+	if !ok {
+		entry = &groupEntry{breaker: g.newBreaker(key)}
+		g.breakers[key] = entry
+	}
+	entry.lastUsed = now
+
+	return entry.breaker
+}
+
+// Execute runs fn through the breaker for key.
+func (g *Group[K]) Execute(key K, fn func() error) error {
+	return g.Get(key).Execute(fn)
+}
+
+// States returns the current state of every breaker in the group.
+func (g *Group[K]) States() map[K]State {
+	g.mu.Lock()
+	g.sweep(g.nowFunc())
+
+	breakers := make(map[K]*CircuitBreaker, len(g.breakers))
+	for key, entry := range g.breakers {
+		breakers[key] = entry.breaker
+	}
+	g.mu.Unlock()
+
+	// Breakers are locked without holding g.mu, so a breaker that is busy
+	// cannot block the rest of the group.
+	states := make(map[K]State, len(breakers))
+	for key, cb := range breakers {
+		states[key] = cb.State()
+	}
+
+	return states
+}
+
+// Breakers returns every breaker currently held by the group, sorted by name.
+// It implements Lister, so a group can be passed to AdminHandler.
+func (g *Group[K]) Breakers() []*CircuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	breakers := make([]*CircuitBreaker, 0, len(g.breakers))
+	for _, entry := range g.breakers {
+		breakers = append(breakers, entry.breaker)
+	}
+	slices.SortFunc(breakers, func(a, b *CircuitBreaker) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return breakers
+}
+
+// Len returns the number of breakers currently held by the group.
+func (g *Group[K]) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.breakers)
+}
+
+// Remove discards the breaker for key. The next Get creates a fresh one.
+func (g *Group[K]) Remove(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.breakers, key)
+}
+
+// newBreaker builds a breaker for key from the template and key overrides,
+// chaining the group callback after any template callback. Breakers are
+// named after their key unless the options set a name.
+func (g *Group[K]) newBreaker(key K) *CircuitBreaker {
+	opts := make([]Option, 0, 1+len(g.options)+len(g.overrides[key]))
+	opts = append(opts, WithName(fmt.Sprint(key)))
+	opts = append(opts, g.options...)
+	opts = append(opts, g.overrides[key]...)
+
+	cb := New(opts...)
+
+	if g.onStateChange != nil {
+		prev := cb.onStateChange
+		cb.onStateChange = func(from, to State) {
+			if prev != nil {
+				prev(from, to)
+			}
+			g.onStateChange(key, from, to)
+		}
+	}
+
+	if g.onCall != nil {
+		prev := cb.onCall
+		cb.onCall = func(outcome Outcome, elapsed time.Duration) {
+			if prev != nil {
+				prev(outcome, elapsed)
+			}
+			g.onCall(key, outcome, elapsed)
+		}
+	}
+
+	return cb
+}
+
+// sweep evicts idle breakers. It runs at most once per idle TTL.
+func (g *Group[K]) sweep(now time.Time) {
+	if g.idleTTL <= 0 || now.Sub(g.lastSweep) < g.idleTTL {
+		return
+	}
+	g.lastSweep = now
+
+	for key, entry := range g.breakers {
+		if now.Sub(entry.lastUsed) >= g.idleTTL {
+			delete(g.breakers, key)
+		}
+	}
+}
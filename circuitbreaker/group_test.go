@@ -0,0 +1,220 @@
+package circuitbreaker_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+func TestGroup_LazilyCreatesPerKeyBreakers(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+	)
+
+	if group.Len() != 0 {
+		t.Fatalf("expected empty group, got %d breakers", group.Len())
+	}
+
+	_ = group.Execute("a.example", func() error { return errDependency })
+	_ = group.Execute("b.example", func() error { return nil })
+
+	if group.Get("a.example") != group.Get("a.example") {
+		t.Fatal("expected the same breaker for the same key")
+	}
+
+	states := group.States()
+	if len(states) != 2 {
+		t.Fatalf("expected 2 breakers, got %d", len(states))
+	}
+	if states["a.example"] != circuitbreaker.StateOpen {
+		t.Fatalf("expected a.example StateOpen, got %v", states["a.example"])
+	}
+	if states["b.example"] != circuitbreaker.StateClosed {
+		t.Fatalf("expected b.example StateClosed, got %v", states["b.example"])
+	}
+}
+
+func TestGroup_KeyOptionsOverrideTemplate(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+		circuitbreaker.WithKeyOptions("tolerant", circuitbreaker.WithThreshold(3)),
+	)
+
+	_ = group.Execute("strict", func() error { return errDependency })
+	_ = group.Execute("tolerant", func() error { return errDependency })
+
+	if got := group.Get("strict").State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("expected strict StateOpen, got %v", got)
+	}
+	if got := group.Get("tolerant").State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("expected tolerant StateClosed, got %v", got)
+	}
+}
+
+func TestGroup_OnStateChangeReceivesKey(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		keys     []string
+		template int
+	)
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](
+			circuitbreaker.WithThreshold(1),
+			circuitbreaker.WithOnStateChange(func(_, _ circuitbreaker.State) {
+				mu.Lock()
+				template++
+				mu.Unlock()
+			}),
+		),
+		circuitbreaker.WithGroupOnStateChange(func(key string, from, to circuitbreaker.State) {
+			if from != circuitbreaker.StateClosed || to != circuitbreaker.StateOpen {
+				t.Errorf("expected Closed→Open, got %v→%v", from, to)
+			}
+			mu.Lock()
+			keys = append(keys, key)
+			mu.Unlock()
+		}),
+	)
+
+	_ = group.Execute("tenant-1", func() error { return errDependency })
+	_ = group.Execute("tenant-2", func() error { return errDependency })
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(keys) != 2 || keys[0] != "tenant-1" || keys[1] != "tenant-2" {
+		t.Fatalf("expected transitions for tenant-1 and tenant-2, got %v", keys)
+	}
+	if template != 2 {
+		t.Fatalf("expected template callback to run twice, got %d", template)
+	}
+}
+
+func TestGroup_OnCallReceivesKey(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		calls    []string
+		template int
+	)
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](
+			circuitbreaker.WithOnCall(func(circuitbreaker.Outcome, time.Duration) {
+				mu.Lock()
+				template++
+				mu.Unlock()
+			}),
+		),
+		circuitbreaker.WithGroupOnCall(func(key string, outcome circuitbreaker.Outcome, _ time.Duration) {
+			mu.Lock()
+			calls = append(calls, key+":"+outcome.String())
+			mu.Unlock()
+		}),
+	)
+
+	_ = group.Execute("tenant-1", func() error { return nil })
+	_ = group.Execute("tenant-2", func() error { return errDependency })
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(calls) != 2 || calls[0] != "tenant-1:success" || calls[1] != "tenant-2:failure" {
+		t.Fatalf("expected keyed outcomes, got %v", calls)
+	}
+	if template != 2 {
+		t.Fatalf("expected template callback to run twice, got %d", template)
+	}
+}
+
+func TestGroup_StatesDoesNotBlockGroupOnBusyBreaker(t *testing.T) {
+	t.Parallel()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+		circuitbreaker.WithGroupOnStateChange(func(string, circuitbreaker.State, circuitbreaker.State) {
+			close(entered)
+			<-release // hold the transitioning breaker's lock
+		}),
+	)
+	group.Get("slow")
+
+	go func() { _ = group.Execute("slow", func() error { return errDependency }) }()
+	<-entered
+
+	states := make(chan map[string]circuitbreaker.State, 1)
+	go func() { states <- group.States() }() // waits for the busy breaker
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		group.Get("other")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Get to proceed while States waits for a busy breaker")
+	}
+
+	close(release)
+	if got := (<-states)["slow"]; got != circuitbreaker.StateOpen {
+		t.Fatalf("expected slow breaker StateOpen, got %v", got)
+	}
+}
+
+func TestGroup_EvictsIdleBreakers(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+		circuitbreaker.WithIdleTTL[string](30*time.Millisecond),
+	)
+
+	_ = group.Execute("idle", func() error { return errDependency })
+
+	time.Sleep(40 * time.Millisecond)
+
+	// Touching another key triggers the sweep.
+	_ = group.Execute("busy", func() error { return nil })
+
+	states := group.States()
+	if _, ok := states["idle"]; ok {
+		t.Fatalf("expected idle breaker to be evicted, got %v", states)
+	}
+	if _, ok := states["busy"]; !ok {
+		t.Fatalf("expected busy breaker to remain, got %v", states)
+	}
+
+	// A fresh breaker is created on next use.
+	if got := group.Get("idle").State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("expected fresh StateClosed breaker, got %v", got)
+	}
+}
+
+func TestGroup_Remove(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup[int]()
+
+	group.Get(1)
+	group.Get(2)
+	group.Remove(1)
+
+	if group.Len() != 1 {
+		t.Fatalf("expected 1 breaker after Remove, got %d", group.Len())
+	}
+}
@@ -0,0 +1,177 @@
+// Package grpc provides gRPC client interceptors that guard calls with
+// circuit breakers from the go-libs circuitbreaker package.
+package grpc
+
+import (
+	"context"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type config struct {
+	group        *circuitbreaker.Group[string]
+	options      []circuitbreaker.Option
+	keyFunc      func(target, method string) string
+	failureCodes map[codes.Code]bool
+}
+
+// Option configures the interceptors.
+type Option func(*config)
+
+// WithBreakerOptions sets the options used for every breaker.
+// Ignored when WithGroup is set.
+func WithBreakerOptions(opts ...circuitbreaker.Option) Option {
+	return func(cfg *config) {
+		cfg.options = append(cfg.options, opts...)
+	}
+}
+
+// WithGroup makes the interceptor use an existing Group, for example to share
+// breakers between the unary and stream interceptors.
+func WithGroup(group *circuitbreaker.Group[string]) Option {
+	return func(cfg *config) {
+		cfg.group = group
+	}
+}
+
+// WithPerMethod keys breakers by target and full method name instead of by
+// target only.
+func WithPerMethod() Option {
+	return func(cfg *config) {
+		cfg.keyFunc = func(target, method string) string {
+			return target + method
+		}
+	}
+}
+
+// WithKeyFunc sets how a call is mapped to a breaker key.
+// Default: the connection target.
+func WithKeyFunc(fn func(target, method string) string) Option {
+	return func(cfg *config) {
+		cfg.keyFunc = fn
+	}
+}
+
+// WithFailureCodes sets the status codes counted as failures. Any other
+// outcome counts as a success.
+// Default: Unavailable, DeadlineExceeded, ResourceExhausted.
+func WithFailureCodes(failureCodes ...codes.Code) Option {
+	return func(cfg *config) {
+		cfg.failureCodes = make(map[codes.Code]bool, len(failureCodes))
+		for _, code := range failureCodes {
+			cfg.failureCodes[code] = true
+		}
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		keyFunc: func(target, _ string) string { return target },
+		failureCodes: map[codes.Code]bool{
+			codes.Unavailable:       true,
+			codes.DeadlineExceeded:  true,
+			codes.ResourceExhausted: true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.group == nil {
+		cfg.group = circuitbreaker.NewGroup(circuitbreaker.WithGroupOptions[string](cfg.options...))
+	}
+
+	return cfg
+}
+
+// classify maps a call error to the outcome reported to the breaker.
+// Calls cancelled by the caller are ignored.
+func (cfg *config) classify(err error) circuitbreaker.Outcome {
+	code := status.Code(err)
+	switch {
+	case cfg.failureCodes[code]:
+		return circuitbreaker.OutcomeFailure
+	case code == codes.Canceled:
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+// allow reserves a call on the breaker for key, translating rejections into
+// codes.Unavailable.
+func (cfg *config) allow(key string) (func(circuitbreaker.Outcome), error) {
+	done, err := cfg.group.Get(key).Allow()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return done, nil
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that guards
+// every unary call with a circuit breaker.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		done, err := cfg.allow(cfg.keyFunc(cc.Target(), method))
+		if err != nil {
+			return err
+		}
+
+		err = invoker(ctx, method, req, reply, cc, callOpts...)
+		done(cfg.classify(err))
+
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that guards
+// every stream with a circuit breaker. A stream counts towards the breaker
+// when it ends, based on the status it ended with: after the last message of
+// a server stream, after CloseAndRecv of a client stream, or when its
+// context is cancelled. Streams the caller abandons must have their context
+// cancelled, as gRPC requires, or their Half-Open probe slot is held until
+// the probe timeout.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		done, err := cfg.allow(cfg.keyFunc(cc.Target(), method))
+		if err != nil {
+			return nil, err
+		}
+
+		callOpts = append(callOpts, grpc.OnFinish(func(err error) {
+			done(cfg.classify(err))
+		}))
+
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			// done ignores the OnFinish report if gRPC also makes one.
+			done(cfg.classify(err))
+			return nil, err
+		}
+
+		return stream, nil
+	}
+}
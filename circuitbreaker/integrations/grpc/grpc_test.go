@@ -0,0 +1,302 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+	breakergrpc "github.com/GabrielNunesIT/go-libs/circuitbreaker/integrations/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// flakyHealthServer fails every call with code while it is non-OK.
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	code  atomic.Uint32
+	calls atomic.Int64
+}
+
+func (s *flakyHealthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.calls.Add(1)
+	if code := codes.Code(s.code.Load()); code != codes.OK {
+		return nil, status.Error(code, "injected failure")
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *flakyHealthServer) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	s.calls.Add(1)
+	if code := codes.Code(s.code.Load()); code != codes.OK {
+		return status.Error(code, "injected failure")
+	}
+
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+// startServer serves srv over an in-process bufconn listener and returns a
+// client connection configured with the given dial options.
+func startServer(t *testing.T, srv healthpb.HealthServer, opts ...grpc.DialOption) healthpb.HealthClient {
+	t.Helper()
+
+	return healthpb.NewHealthClient(dial(t, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, srv)
+	}, opts...))
+}
+
+// dial serves the services added by register over an in-process bufconn
+// listener and returns a client connection using the given dial options.
+func dial(t *testing.T, register func(server *grpc.Server), opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	register(server)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestUnaryClientInterceptor_OpensOnFailureCodes(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{}
+	srv.code.Store(uint32(codes.Unavailable))
+
+	client := startServer(t, srv, grpc.WithUnaryInterceptor(breakergrpc.UnaryClientInterceptor(
+		breakergrpc.WithBreakerOptions(circuitbreaker.WithThreshold(2)),
+	)))
+
+	for range 2 {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("expected injected Unavailable, got %v", err)
+		}
+	}
+
+	_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable from open circuit, got %v", err)
+	}
+	if msg := status.Convert(err).Message(); !strings.HasPrefix(msg, `circuit breaker "passthrough:///bufnet" is open`) {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Fatalf("expected open circuit to skip the server, got %d calls", got)
+	}
+}
+
+func TestUnaryClientInterceptor_IgnoresOtherCodes(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{}
+	srv.code.Store(uint32(codes.NotFound))
+
+	client := startServer(t, srv, grpc.WithUnaryInterceptor(breakergrpc.UnaryClientInterceptor(
+		breakergrpc.WithBreakerOptions(circuitbreaker.WithThreshold(1)),
+	)))
+
+	for range 3 {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("expected NotFound to pass through, got %v", err)
+		}
+	}
+}
+
+func TestUnaryClientInterceptor_PerMethodKeys(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{}
+	srv.code.Store(uint32(codes.NotFound))
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+	)
+	client := startServer(t, srv, grpc.WithUnaryInterceptor(breakergrpc.UnaryClientInterceptor(
+		breakergrpc.WithGroup(group),
+		breakergrpc.WithPerMethod(),
+		breakergrpc.WithFailureCodes(codes.NotFound),
+	)))
+
+	_, _ = client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+
+	key := "passthrough:///bufnet" + healthpb.Health_Check_FullMethodName
+	if got := group.States()[key]; got != circuitbreaker.StateOpen {
+		t.Fatalf("expected breaker %q to be open, got %v", key, group.States())
+	}
+}
+
+func TestStreamClientInterceptor_OpensOnFailedStreams(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{}
+	srv.code.Store(uint32(codes.ResourceExhausted))
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(1)),
+	)
+	client := startServer(t, srv, grpc.WithStreamInterceptor(breakergrpc.StreamClientInterceptor(
+		breakergrpc.WithGroup(group),
+	)))
+
+	stream, err := client.Watch(t.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected stream to open, got %v", err)
+	}
+	if _, err = stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected injected ResourceExhausted, got %v", err)
+	}
+
+	if got := group.Get("passthrough:///bufnet").State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("expected breaker to open after the failed stream, got %v", got)
+	}
+
+	_, err = client.Watch(t.Context(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable from open circuit, got %v", err)
+	}
+}
+
+func TestStreamClientInterceptor_CountsSuccessfulStreams(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup[string]()
+	client := startServer(t, &flakyHealthServer{}, grpc.WithStreamInterceptor(breakergrpc.StreamClientInterceptor(
+		breakergrpc.WithGroup(group),
+	)))
+
+	stream, err := client.Watch(t.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected stream to open, got %v", err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatalf("expected a message, got %v", err)
+	}
+	if _, err = stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if counts := group.Get("passthrough:///bufnet").Counts(); counts.TotalSuccesses != 1 {
+		t.Fatalf("expected 1 success, got %+v", counts)
+	}
+}
+
+// streamingInputServer accepts client streams and answers once they close.
+type streamingInputServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (streamingInputServer) StreamingInputCall(stream testpb.TestService_StreamingInputCallServer) error {
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return stream.SendAndClose(&testpb.StreamingInputCallResponse{})
+			}
+			return err
+		}
+	}
+}
+
+func TestStreamClientInterceptor_ClientStreamClosesHalfOpenBreaker(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup(circuitbreaker.WithGroupOptions[string](
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Millisecond),
+	))
+	cb := group.Get("passthrough:///bufnet")
+	_ = cb.Execute(func() error { return errors.New("dependency failed") })
+	time.Sleep(5 * time.Millisecond)
+
+	conn := dial(t, func(server *grpc.Server) {
+		testpb.RegisterTestServiceServer(server, streamingInputServer{})
+	}, grpc.WithStreamInterceptor(breakergrpc.StreamClientInterceptor(breakergrpc.WithGroup(group))))
+
+	stream, err := testpb.NewTestServiceClient(conn).StreamingInputCall(t.Context())
+	if err != nil {
+		t.Fatalf("expected the probe stream to open, got %v", err)
+	}
+	if err = stream.Send(&testpb.StreamingInputCallRequest{}); err != nil {
+		t.Fatalf("expected Send to succeed, got %v", err)
+	}
+	if _, err = stream.CloseAndRecv(); err != nil {
+		t.Fatalf("expected CloseAndRecv to succeed, got %v", err)
+	}
+
+	if got := cb.State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("expected the successful client stream to close the breaker, got %v", got)
+	}
+}
+
+func TestStreamClientInterceptor_CancelledStreamReleasesProbe(t *testing.T) {
+	t.Parallel()
+
+	outcomes := make(chan circuitbreaker.Outcome, 1)
+	group := circuitbreaker.NewGroup(circuitbreaker.WithGroupOptions[string](
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Millisecond),
+		circuitbreaker.WithOnCall(func(outcome circuitbreaker.Outcome, _ time.Duration) {
+			if outcome != circuitbreaker.OutcomeRejected {
+				outcomes <- outcome
+			}
+		}),
+	))
+	cb := group.Get("passthrough:///bufnet")
+	_ = cb.Execute(func() error { return errors.New("dependency failed") })
+	<-outcomes
+	time.Sleep(5 * time.Millisecond)
+
+	client := startServer(t, &flakyHealthServer{}, grpc.WithStreamInterceptor(breakergrpc.StreamClientInterceptor(
+		breakergrpc.WithGroup(group),
+	)))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	if _, err := client.Watch(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("expected the probe stream to open, got %v", err)
+	}
+	cancel() // abandon the stream without reading to EOF
+
+	select {
+	case outcome := <-outcomes:
+		if outcome != circuitbreaker.OutcomeIgnored {
+			t.Fatalf("expected the cancelled stream to be ignored, got %v", outcome)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the cancelled stream to report its outcome")
+	}
+
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected the released probe slot to allow a call, got %v", err)
+	}
+	done(circuitbreaker.OutcomeSuccess)
+}
@@ -0,0 +1,333 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrUnnamedBreaker is reported to WithOnStoreError when WithStateStore is
+// used without WithName. Shared state is keyed by name, so the breaker stays
+// local.
+var ErrUnnamedBreaker = errors.New("circuit breaker sharing state must be named")
+
+// SharedState is what breakers with the same name publish to a StateStore.
+type SharedState struct {
+	// State is StateOpen after a trip and StateClosed after a recovery.
+	State State `json:"state"`
+	// OpenUntil is when the published open period ends.
+	OpenUntil time.Time `json:"open_until"`
+	// Successes and Failures are totals reported by all replicas.
+	Successes uint64 `json:"successes"`
+	Failures  uint64 `json:"failures"`
+	// UpdatedAt is when State was last published; zero if never.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StateStore shares breaker state between replicas. Breakers publish trips
+// and recoveries and periodically add their counts, then adopt newer trips or
+// recoveries published by other replicas. The model is eventually consistent:
+// a replica learns about a change at its next sync.
+type StateStore interface {
+	// Update atomically replaces the shared state for name with fn's result
+	// and returns it.
+	Update(name string, fn func(SharedState) SharedState) (SharedState, error)
+}
+
+// WithStateStore shares this breaker's state with other breakers of the same
+// name through store. The breaker must be named with WithName; otherwise New
+// ignores the store and reports ErrUnnamedBreaker to WithOnStoreError. If the
+// store fails, the breaker keeps working on its local state.
+//
+// The store is never called with the breaker locked: syncs run on the calling
+// goroutine in Allow or Execute, and trips and recoveries are published by
+// the goroutine reporting the outcome. A slow store therefore delays only
+// those calls.
+func WithStateStore(store StateStore) Option {
+	return func(cb *CircuitBreaker) {
+		cb.store = store
+	}
+}
+
+// WithSyncInterval sets how often the breaker adds its counts to the store
+// and picks up state published by other replicas. A breaker whose open
+// timeout has elapsed always syncs before probing, so that only one replica
+// probes a dependency another replica found still broken.
+// Default: 1s.
+func WithSyncInterval(d time.Duration) Option {
+	return func(cb *CircuitBreaker) {
+		cb.syncInterval = d
+	}
+}
+
+// WithOnStoreError registers a callback invoked when the StateStore fails,
+// or with ErrUnnamedBreaker when a store is given to an unnamed breaker.
+// It runs without the breaker's lock held.
+func WithOnStoreError(fn func(err error)) Option {
+	return func(cb *CircuitBreaker) {
+		cb.onStoreError = fn
+	}
+}
+
+// SharedState returns the shared state seen at the last successful sync.
+func (cb *CircuitBreaker) SharedState() SharedState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.shared
+}
+
+// sync syncs with the store when the interval has passed or the breaker is
+// about to probe. The store is called without holding mu, and only one sync
+// runs at a time; meanwhile an Open breaker keeps rejecting calls.
+func (cb *CircuitBreaker) sync(now time.Time) {
+	cb.mu.Lock()
+
+	if cb.store == nil || cb.syncing {
+		cb.mu.Unlock()
+		return
+	}
+
+	probing := cb.state == StateOpen && now.Sub(cb.lastFailure) >= cb.openTimeout
+	if !probing && now.Sub(cb.lastSync) < cb.syncInterval {
+		cb.mu.Unlock()
+		return
+	}
+
+	cb.syncing = true
+	cb.lastSync = now
+	successes, failures := cb.unsynced.TotalSuccesses, cb.unsynced.TotalFailures
+	cb.unsynced = Counts{}
+	cb.mu.Unlock()
+
+	shared, err := cb.store.Update(cb.name, func(state SharedState) SharedState {
+		state.Successes += successes
+		state.Failures += failures
+		return state
+	})
+
+	cb.mu.Lock()
+	cb.syncing = false
+	if err != nil {
+		// Keep the counts for the next sync.
+		cb.unsynced.TotalSuccesses += successes
+		cb.unsynced.TotalFailures += failures
+	} else {
+		cb.shared = shared
+		cb.adopt(shared, cb.nowFunc())
+	}
+	cb.mu.Unlock()
+
+	if err != nil {
+		cb.storeError(err)
+	}
+}
+
+// adopt applies a trip or recovery published by another replica after this
+// breaker's last transition. Callers must hold mu.
+func (cb *CircuitBreaker) adopt(shared SharedState, now time.Time) {
+	if !shared.UpdatedAt.After(cb.changedAt) {
+		return
+	}
+
+	switch shared.State {
+	case StateOpen:
+		if !shared.OpenUntil.After(now) {
+			return
+		}
+
+		cb.lastFailure = now
+		cb.openTimeout = shared.OpenUntil.Sub(now)
+
+		switch cb.state {
+		case StateClosed, StateHalfOpen:
+			cb.transitionTo(StateOpen)
+		case StateOpen:
+			// Another replica's probe failed: extend the open period.
+			cb.changedAt = now
+		case StateForcedOpen, StateForcedClosed:
+		}
+	case StateClosed:
+		if cb.state == StateOpen || cb.state == StateHalfOpen {
+			cb.backoff = cb.timeout
+			cb.openTimeout = cb.timeout
+			cb.transitionTo(StateClosed)
+		}
+	case StateHalfOpen, StateForcedOpen, StateForcedClosed:
+	}
+}
+
+// stagePublish records a local trip or recovery to be published once mu is
+// released. Callers must hold mu.
+func (cb *CircuitBreaker) stagePublish() {
+	if cb.store == nil {
+		return
+	}
+
+	published := SharedState{State: cb.state, UpdatedAt: cb.changedAt}
+	if cb.state == StateOpen {
+		published.OpenUntil = cb.lastFailure.Add(cb.openTimeout)
+	}
+	cb.pending = &published
+}
+
+// publish writes a staged trip or recovery to the store. It must be called
+// without holding mu. A state published later by this or another replica is
+// kept, so concurrent publishes cannot go back in time.
+func (cb *CircuitBreaker) publish(published SharedState) {
+	shared, err := cb.store.Update(cb.name, func(state SharedState) SharedState {
+		if published.UpdatedAt.Before(state.UpdatedAt) {
+			return state
+		}
+
+		published.Successes = state.Successes
+		published.Failures = state.Failures
+		return published
+	})
+	if err != nil {
+		cb.storeError(err)
+		return
+	}
+
+	cb.mu.Lock()
+	if !shared.UpdatedAt.Before(cb.shared.UpdatedAt) {
+		cb.shared = shared
+	}
+	cb.mu.Unlock()
+}
+
+func (cb *CircuitBreaker) storeError(err error) {
+	if cb.onStoreError != nil {
+		cb.onStoreError(err)
+	}
+}
+
+// MemoryStore is an in-process StateStore. Breakers sharing one MemoryStore
+// behave like replicas sharing a remote store, which makes it useful in tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]SharedState
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]SharedState)}
+}
+
+// Load returns the shared state for name, or the zero SharedState if nothing
+// was published yet.
+func (s *MemoryStore) Load(name string) (SharedState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[name], nil
+}
+
+// Update implements StateStore.
+func (s *MemoryStore) Update(name string, fn func(SharedState) SharedState) (SharedState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := fn(s.states[name])
+	s.states[name] = state
+
+	return state, nil
+}
+
+// fileLocks serializes updates to the same file across FileStore instances
+// in one process.
+var fileLocks sync.Map // map[string]*sync.Mutex
+
+// FileStore is a StateStore keeping one JSON file per breaker name in a
+// directory. Updates are atomic within a process; across processes the last
+// writer wins, which the eventually consistent model tolerates.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore writing to dir. The directory must exist.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Load returns the shared state for name, or the zero SharedState if nothing
+// was published yet.
+func (s *FileStore) Load(name string) (SharedState, error) {
+	path := s.path(name)
+
+	lock := s.lock(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return s.read(path)
+}
+
+// Update implements StateStore.
+func (s *FileStore) Update(name string, fn func(SharedState) SharedState) (SharedState, error) {
+	path := s.path(name)
+
+	lock := s.lock(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	state, err := s.read(path)
+	if err != nil {
+		return SharedState{}, err
+	}
+
+	state = fn(state)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: encode shared state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".state-*")
+	if err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: write shared state: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return SharedState{}, fmt.Errorf("circuitbreaker: write shared state: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: write shared state: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: write shared state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (s *FileStore) path(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+".json")
+}
+
+func (s *FileStore) lock(path string) *sync.Mutex {
+	lock, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	return lock.(*sync.Mutex) //nolint:forcetypeassert // fileLocks only holds *sync.Mutex
+}
+
+func (s *FileStore) read(path string) (SharedState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is built from the store directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return SharedState{}, nil
+	}
+	if err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: read shared state: %w", err)
+	}
+
+	var state SharedState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return SharedState{}, fmt.Errorf("circuitbreaker: decode shared state: %w", err)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,279 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+var errStore = errors.New("store unavailable")
+
+// replicas returns two breakers with the same name sharing state through the
+// given stores.
+func replicas(a, b circuitbreaker.StateStore, opts ...circuitbreaker.Option) (*circuitbreaker.CircuitBreaker, *circuitbreaker.CircuitBreaker) {
+	base := []circuitbreaker.Option{
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithSyncInterval(0),
+	}
+	base = append(base, opts...)
+
+	return circuitbreaker.New(append(base, circuitbreaker.WithStateStore(a))...),
+		circuitbreaker.New(append(base, circuitbreaker.WithStateStore(b))...)
+}
+
+func TestStateStore_TripIsSharedAcrossReplicas(t *testing.T) {
+	t.Parallel()
+
+	store := circuitbreaker.NewMemoryStore()
+	first, second := replicas(store, store, circuitbreaker.WithTimeout(time.Minute))
+
+	_ = first.Execute(func() error { return errDependency })
+
+	var called bool
+	err := second.Execute(func() error {
+		called = true
+		return nil
+	})
+
+	if called {
+		t.Fatal("expected second replica to reject without calling fn")
+	}
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := second.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("expected second replica StateOpen, got %v", got)
+	}
+}
+
+func TestStateStore_RecoveryIsSharedAcrossReplicas(t *testing.T) {
+	t.Parallel()
+
+	store := circuitbreaker.NewMemoryStore()
+	first, second := replicas(store, store, circuitbreaker.WithTimeout(30*time.Millisecond))
+
+	_ = first.Execute(func() error { return errDependency })
+	_ = second.Execute(func() error { return nil }) // adopts the trip
+
+	time.Sleep(40 * time.Millisecond)
+
+	if err := first.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+
+	var transitions []circuitbreaker.State
+	events, unsubscribe := second.Subscribe(4)
+	defer unsubscribe()
+
+	if err := second.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("expected second replica to allow calls, got %v", err)
+	}
+
+	for len(events) > 0 {
+		if event := <-events; event.Type == circuitbreaker.EventStateChanged {
+			transitions = append(transitions, event.To)
+		}
+	}
+
+	// The second replica closes on the first replica's probe instead of probing itself.
+	if len(transitions) != 1 || transitions[0] != circuitbreaker.StateClosed {
+		t.Fatalf("expected a single transition to StateClosed, got %v", transitions)
+	}
+}
+
+func TestStateStore_FileStoreSharesState(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first, second := replicas(
+		circuitbreaker.NewFileStore(dir),
+		circuitbreaker.NewFileStore(dir),
+		circuitbreaker.WithTimeout(time.Minute),
+	)
+
+	_ = first.Execute(func() error { return errDependency })
+
+	if err := second.Execute(func() error { return nil }); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	shared, err := circuitbreaker.NewFileStore(dir).Load("payments")
+	if err != nil {
+		t.Fatalf("expected Load to succeed, got %v", err)
+	}
+	if shared.State != circuitbreaker.StateOpen {
+		t.Fatalf("expected stored StateOpen, got %v", shared.State)
+	}
+	if !shared.OpenUntil.After(time.Now()) {
+		t.Fatalf("expected stored open period in the future, got %v", shared.OpenUntil)
+	}
+}
+
+func TestStateStore_AggregatesCounts(t *testing.T) {
+	t.Parallel()
+
+	store := circuitbreaker.NewMemoryStore()
+	first, second := replicas(store, store, circuitbreaker.WithThreshold(10))
+
+	for range 3 {
+		_ = first.Execute(func() error { return nil })
+	}
+	_ = second.Execute(func() error { return errDependency })
+
+	// Counts are added on the next sync.
+	_ = first.Execute(func() error { return nil })
+	_ = second.Execute(func() error { return nil })
+
+	shared := second.SharedState()
+	if shared.Successes != 3 || shared.Failures != 1 {
+		t.Fatalf("expected 3 successes and 1 failure, got %+v", shared)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Update(string, func(circuitbreaker.SharedState) circuitbreaker.SharedState) (circuitbreaker.SharedState, error) {
+	return circuitbreaker.SharedState{}, errStore
+}
+
+func TestStateStore_FallsBackToLocalState(t *testing.T) {
+	t.Parallel()
+
+	var storeErrors atomic.Int64
+	cb := circuitbreaker.New(
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithSyncInterval(0),
+		circuitbreaker.WithStateStore(failingStore{}),
+		circuitbreaker.WithOnStoreError(func(err error) {
+			if !errors.Is(err, errStore) {
+				t.Errorf("expected errStore, got %v", err)
+			}
+			storeErrors.Add(1)
+		}),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+
+	if got := cb.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("expected local StateOpen, got %v", got)
+	}
+	if storeErrors.Load() == 0 {
+		t.Fatal("expected store errors to be reported")
+	}
+}
+
+// slowStore is a MemoryStore whose Update blocks until release is closed,
+// signalling entered each time an update starts.
+type slowStore struct {
+	*circuitbreaker.MemoryStore
+
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStore) Update(name string, fn func(circuitbreaker.SharedState) circuitbreaker.SharedState) (circuitbreaker.SharedState, error) {
+	s.entered <- struct{}{}
+	<-s.release
+
+	return s.MemoryStore.Update(name, fn)
+}
+
+func TestStateStore_SlowStoreDoesNotBlockOtherCalls(t *testing.T) {
+	t.Parallel()
+
+	store := &slowStore{
+		MemoryStore: circuitbreaker.NewMemoryStore(),
+		entered:     make(chan struct{}, 4),
+		release:     make(chan struct{}),
+	}
+	cb := circuitbreaker.New(
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithSyncInterval(0),
+		circuitbreaker.WithStateStore(store),
+	)
+
+	syncing := make(chan error, 1)
+	go func() { syncing <- cb.Execute(func() error { return nil }) }()
+	<-store.entered
+
+	done := make(chan error, 1)
+	go func() {
+		_ = cb.State()
+		done <- cb.Execute(func() error { return nil })
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the call to succeed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected calls to proceed while the store is slow")
+	}
+
+	close(store.release)
+	if err := <-syncing; err != nil {
+		t.Fatalf("expected the syncing call to succeed, got %v", err)
+	}
+}
+
+func TestStateStore_RejectsWhileProbeSyncInFlight(t *testing.T) {
+	t.Parallel()
+
+	store := &slowStore{
+		MemoryStore: circuitbreaker.NewMemoryStore(),
+		entered:     make(chan struct{}, 4),
+		release:     make(chan struct{}),
+	}
+	close(store.release)
+
+	cb := circuitbreaker.New(
+		circuitbreaker.WithName("payments"),
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Millisecond),
+		circuitbreaker.WithSyncInterval(time.Hour),
+		circuitbreaker.WithStateStore(store),
+	)
+
+	_ = cb.Execute(func() error { return errDependency })
+	<-store.entered // the first sync
+	<-store.entered // the published trip
+	time.Sleep(5 * time.Millisecond)
+
+	store.release = make(chan struct{})
+
+	probe := make(chan error, 1)
+	go func() { probe <- cb.Execute(func() error { return nil }) }()
+	<-store.entered
+
+	if err := cb.Execute(func() error { return nil }); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen while the probe syncs, got %v", err)
+	}
+
+	close(store.release)
+	if err := <-probe; err != nil {
+		t.Fatalf("expected the probe to run after the sync, got %v", err)
+	}
+}
+
+func TestStateStore_UnnamedBreakerIsReported(t *testing.T) {
+	t.Parallel()
+
+	var reported error
+	cb := circuitbreaker.New(
+		circuitbreaker.WithStateStore(circuitbreaker.NewMemoryStore()),
+		circuitbreaker.WithOnStoreError(func(err error) { reported = err }),
+	)
+
+	if !errors.Is(reported, circuitbreaker.ErrUnnamedBreaker) {
+		t.Fatalf("expected ErrUnnamedBreaker, got %v", reported)
+	}
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("expected the breaker to work locally, got %v", err)
+	}
+}
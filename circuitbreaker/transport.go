@@ -0,0 +1,174 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HostOpenError is returned by Transport when the breaker for a host is open.
+// It unwraps to the breaker's *OpenError and matches errors.Is(err, ErrCircuitOpen).
+type HostOpenError struct {
+	// Host is the breaker key the request was routed to.
+	Host string
+	// Remaining is how long the circuit stays open before the next probe.
+	Remaining time.Duration
+
+	err *OpenError
+}
+
+// Error implements the error interface.
+func (e *HostOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open for host %s (next probe in %s)", e.Host, e.Remaining)
+}
+
+// Unwrap returns the breaker's *OpenError, or ErrCircuitOpen if there is none.
+func (e *HostOpenError) Unwrap() error {
+	if e.err == nil {
+		return ErrCircuitOpen
+	}
+
+	return e.err
+}
+
+type transport struct {
+	base     http.RoundTripper
+	group    *Group[string]
+	options  []Option
+	hostFunc func(req *http.Request) string
+	classify func(resp *http.Response, err error) Outcome
+}
+
+// TransportOption configures the RoundTripper returned by Transport.
+type TransportOption func(*transport)
+
+// WithBreakerOptions sets the options used for every per-host breaker.
+// Ignored when WithHostGroup is set.
+func WithBreakerOptions(opts ...Option) TransportOption {
+	return func(t *transport) {
+		t.options = append(t.options, opts...)
+	}
+}
+
+// WithHostGroup makes the transport use an existing Group, for example to
+// share breakers between clients or to read their States().
+func WithHostGroup(group *Group[string]) TransportOption {
+	return func(t *transport) {
+		t.group = group
+	}
+}
+
+// WithHostFunc sets how a request is mapped to a breaker key.
+// Default: req.URL.Host.
+func WithHostFunc(fn func(req *http.Request) string) TransportOption {
+	return func(t *transport) {
+		t.hostFunc = fn
+	}
+}
+
+// WithResponseClassifier sets how a round trip is mapped to the Outcome
+// reported to the host's breaker. Default: DefaultResponseClassifier.
+func WithResponseClassifier(fn func(resp *http.Response, err error) Outcome) TransportOption {
+	return func(t *transport) {
+		t.classify = fn
+	}
+}
+
+// WithIsFailure sets how a round trip outcome is classified. Returning true
+// counts the call as a failure; otherwise it counts as a success, except for
+// requests cancelled by the caller, which are ignored.
+// Default: DefaultIsFailure.
+func WithIsFailure(fn func(resp *http.Response, err error) bool) TransportOption {
+	return func(t *transport) {
+		t.classify = func(resp *http.Response, err error) Outcome {
+			switch {
+			case fn(resp, err):
+				return OutcomeFailure
+			case errors.Is(err, context.Canceled):
+				return OutcomeIgnored
+			default:
+				return OutcomeSuccess
+			}
+		}
+	}
+}
+
+// DefaultIsFailure counts transport errors, 5xx and 429 responses as failures.
+// Requests cancelled by the caller are not failures.
+func DefaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// DefaultResponseClassifier counts failures as DefaultIsFailure does and
+// ignores requests cancelled by the caller, so that a cancelled Half-Open
+// probe neither closes nor reopens the breaker.
+func DefaultResponseClassifier(resp *http.Response, err error) Outcome {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return OutcomeIgnored
+	case DefaultIsFailure(resp, err):
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Transport wraps base with a circuit breaker per host. Requests to a host
+// whose breaker is open fail with a *HostOpenError without reaching base.
+// Responses classified as failures are still returned to the caller.
+// If base is nil, http.DefaultTransport is used.
+//
+//nolint:ireturn // designed to be assigned to http.Client.Transport
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	t := &transport{
+		base:     base,
+		hostFunc: func(req *http.Request) string { return req.URL.Host },
+		classify: DefaultResponseClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if t.group == nil {
+		t.group = NewGroup(WithGroupOptions[string](t.options...))
+	}
+
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := t.hostFunc(req)
+	done, err := t.group.Get(host).Allow()
+	if err != nil {
+		// RoundTrip must close the body even when the request is not sent.
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		var openErr *OpenError
+		if errors.As(err, &openErr) {
+			return nil, &HostOpenError{Host: host, Remaining: openErr.RetryAfter, err: openErr}
+		}
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	done(t.classify(resp, err))
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // transport errors are returned unchanged
+	}
+
+	return resp, nil
+}
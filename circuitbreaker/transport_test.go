@@ -0,0 +1,260 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/circuitbreaker"
+)
+
+// newFlakyServer returns a server that answers with the status stored in status.
+func newFlakyServer(t *testing.T, status *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func doGet(t *testing.T, client *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	return resp, err
+}
+
+func TestTransport_TripsOn5xx(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int64
+	status.Store(http.StatusServiceUnavailable)
+	server := newFlakyServer(t, &status)
+
+	client := &http.Client{Transport: circuitbreaker.Transport(nil,
+		circuitbreaker.WithBreakerOptions(
+			circuitbreaker.WithThreshold(2),
+			circuitbreaker.WithTimeout(time.Minute),
+		),
+	)}
+
+	// Failing responses are still handed back to the caller.
+	for range 2 {
+		resp, err := doGet(t, client, server.URL)
+		if err != nil {
+			t.Fatalf("expected response, got error %v", err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", resp.StatusCode)
+		}
+	}
+
+	_, err := doGet(t, client, server.URL)
+
+	var openErr *circuitbreaker.HostOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected *HostOpenError, got %v", err)
+	}
+	if !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected error to match ErrCircuitOpen, got %v", err)
+	}
+	if openErr.Host != strings.TrimPrefix(server.URL, "http://") {
+		t.Fatalf("expected host %q, got %q", server.URL, openErr.Host)
+	}
+	if openErr.Remaining <= 0 || openErr.Remaining > time.Minute {
+		t.Fatalf("expected remaining open time within (0, 1m], got %v", openErr.Remaining)
+	}
+}
+
+func TestTransport_RecoversAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int64
+	status.Store(http.StatusTooManyRequests)
+	server := newFlakyServer(t, &status)
+
+	client := &http.Client{Transport: circuitbreaker.Transport(http.DefaultTransport,
+		circuitbreaker.WithBreakerOptions(
+			circuitbreaker.WithThreshold(1),
+			circuitbreaker.WithTimeout(30*time.Millisecond),
+		),
+	)}
+
+	_, _ = doGet(t, client, server.URL)
+	if _, err := doGet(t, client, server.URL); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after 429, got %v", err)
+	}
+
+	status.Store(http.StatusOK)
+	time.Sleep(40 * time.Millisecond)
+
+	resp, err := doGet(t, client, server.URL)
+	if err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestTransport_TransportErrorsCountAsFailures(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int64
+	status.Store(http.StatusOK)
+	server := newFlakyServer(t, &status)
+	url := server.URL
+	server.Close() // connection refused from now on
+
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.WithGroupOptions[string](circuitbreaker.WithThreshold(2)),
+	)
+	client := &http.Client{Transport: circuitbreaker.Transport(nil, circuitbreaker.WithHostGroup(group))}
+
+	for range 2 {
+		_, err := doGet(t, client, url)
+		if err == nil || errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+			t.Fatalf("expected a transport error, got %v", err)
+		}
+	}
+
+	host := strings.TrimPrefix(url, "http://")
+	if got := group.States()[host]; got != circuitbreaker.StateOpen {
+		t.Fatalf("expected breaker for %s to be open, got %v", host, got)
+	}
+}
+
+func TestTransport_PerHostIsolationAndCustomClassifier(t *testing.T) {
+	t.Parallel()
+
+	var badStatus, goodStatus atomic.Int64
+	badStatus.Store(http.StatusNotFound)
+	goodStatus.Store(http.StatusOK)
+	bad := newFlakyServer(t, &badStatus)
+	good := newFlakyServer(t, &goodStatus)
+
+	client := &http.Client{Transport: circuitbreaker.Transport(nil,
+		circuitbreaker.WithBreakerOptions(circuitbreaker.WithThreshold(1)),
+		circuitbreaker.WithIsFailure(func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode == http.StatusNotFound
+		}),
+	)}
+
+	_, _ = doGet(t, client, bad.URL)
+
+	if _, err := doGet(t, client, bad.URL); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected bad host to be open, got %v", err)
+	}
+	if _, err := doGet(t, client, good.URL); err != nil {
+		t.Fatalf("expected good host to be unaffected, got %v", err)
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport_CancelledProbeIsIgnored(t *testing.T) {
+	t.Parallel()
+
+	var fail atomic.Bool
+	fail.Store(true)
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if fail.Load() {
+			return nil, errors.New("connection refused")
+		}
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	group := circuitbreaker.NewGroup(circuitbreaker.WithGroupOptions[string](
+		circuitbreaker.WithThreshold(1),
+		circuitbreaker.WithTimeout(time.Millisecond),
+	))
+	client := &http.Client{Transport: circuitbreaker.Transport(base, circuitbreaker.WithHostGroup(group))}
+
+	_, _ = doGet(t, client, "http://backend.invalid")
+	cb := group.Get("backend.invalid")
+	if state := cb.State(); state != circuitbreaker.StateOpen && state != circuitbreaker.StateHalfOpen {
+		t.Fatalf("expected the breaker to trip, got %v", state)
+	}
+
+	fail.Store(false)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://backend.invalid", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	go cancel()
+
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if state := cb.State(); state == circuitbreaker.StateClosed {
+		t.Fatal("expected a cancelled probe not to close the breaker")
+	}
+	if counts := cb.Counts(); counts.TotalSuccesses != 0 || counts.TotalFailures != 1 {
+		t.Fatalf("expected the cancelled probe to be ignored, got %+v", counts)
+	}
+}
+
+// closeTracker is a request body that records whether it was closed.
+type closeTracker struct {
+	io.Reader
+
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestTransport_ClosesBodyOfRejectedRequests(t *testing.T) {
+	t.Parallel()
+
+	group := circuitbreaker.NewGroup[string]()
+	group.Get("backend.invalid").ForceOpen()
+
+	base := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Error("expected the rejected request not to be sent")
+		return nil, errors.New("unexpected round trip")
+	})
+	rt := circuitbreaker.Transport(base, circuitbreaker.WithHostGroup(group))
+
+	body := &closeTracker{Reader: strings.NewReader("payload")}
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://backend.invalid", body)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	if _, err = rt.RoundTrip(req); !errors.Is(err, circuitbreaker.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if !body.closed.Load() {
+		t.Fatal("expected the request body to be closed")
+	}
+}
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rejectedOutcome is the String() of circuitbreaker.OutcomeRejected.
+const rejectedOutcome = "rejected"
+
+// BreakerEnum is satisfied by the circuitbreaker.State and
+// circuitbreaker.Outcome types, letting this package instrument breakers
+// without depending on the circuitbreaker module.
+type BreakerEnum interface {
+	~int
+	String() string
+}
+
+// CircuitBreakerMetrics provides Prometheus metrics for any number of named
+// circuit breakers sharing one Registry. Every series carries a "name" label,
+// so registering many breakers never registers a metric twice.
+type CircuitBreakerMetrics struct {
+	state       *prometheus.GaugeVec
+	transitions *prometheus.CounterVec
+	calls       *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	buckets     []float64
+}
+
+// CircuitBreakerOption configures CircuitBreakerMetrics.
+type CircuitBreakerOption func(*CircuitBreakerMetrics)
+
+// WithCircuitBreakerBuckets overrides the default histogram buckets for
+// guarded call duration tracking.
+func WithCircuitBreakerBuckets(buckets []float64) CircuitBreakerOption {
+	return func(m *CircuitBreakerMetrics) {
+		m.buckets = buckets
+	}
+}
+
+// NewCircuitBreakerMetrics creates and registers the circuit breaker metrics
+// on the given Registry. It may be called more than once per Registry, for
+// example by packages instrumenting their own breakers: later calls reuse the
+// metrics registered by the first one, including its histogram buckets.
+// The following metrics are created:
+//
+//   - circuit_breaker_state (gauge vec: name) — numeric state, 0=closed, 1=open,
+//     2=half-open, 3=forced-open, 4=forced-closed
+//   - circuit_breaker_transitions_total (counter vec: name, from, to)
+//   - circuit_breaker_calls_total (counter vec: name, outcome) — success|failure|ignored|rejected
+//   - circuit_breaker_call_duration_seconds (histogram vec: name, outcome)
+//
+// Wire a breaker to it with BreakerStateObserver and BreakerCallObserver:
+//
+//	m := metrics.NewCircuitBreakerMetrics(reg)
+//	cb := circuitbreaker.New(
+//	    circuitbreaker.WithName("payments"),
+//	    circuitbreaker.WithOnStateChange(metrics.BreakerStateObserver[circuitbreaker.State](m, "payments")),
+//	    circuitbreaker.WithOnCall(metrics.BreakerCallObserver[circuitbreaker.Outcome](m, "payments")),
+//	)
+//
+// Wire a Group, or the per-host circuitbreaker.Transport through
+// WithHostGroup, with GroupStateObserver and GroupCallObserver:
+//
+//	group := circuitbreaker.NewGroup(
+//	    circuitbreaker.WithGroupOnStateChange(metrics.GroupStateObserver[string, circuitbreaker.State](m)),
+//	    circuitbreaker.WithGroupOnCall(metrics.GroupCallObserver[string, circuitbreaker.Outcome](m)),
+//	)
+//	client := &http.Client{Transport: circuitbreaker.Transport(nil, circuitbreaker.WithHostGroup(group))}
+func NewCircuitBreakerMetrics(reg *Registry, opts ...CircuitBreakerOption) *CircuitBreakerMetrics {
+	breakerMetrics := &CircuitBreakerMetrics{
+		buckets: DefaultHistogramBuckets,
+	}
+
+	for _, opt := range opts {
+		opt(breakerMetrics)
+	}
+
+	breakerMetrics.state = registerOrReuse(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: reg.namespace,
+		Subsystem: reg.subsystem,
+		Name:      "circuit_breaker_state",
+		Help:      "Current circuit breaker state (0=closed, 1=open, 2=half-open, 3=forced-open, 4=forced-closed).",
+	}, []string{"name"}))
+	breakerMetrics.transitions = registerOrReuse(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: reg.namespace,
+		Subsystem: reg.subsystem,
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "Total number of circuit breaker state transitions.",
+	}, []string{"name", "from", "to"}))
+	breakerMetrics.calls = registerOrReuse(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: reg.namespace,
+		Subsystem: reg.subsystem,
+		Name:      "circuit_breaker_calls_total",
+		Help:      "Total number of calls through the circuit breaker by outcome.",
+	}, []string{"name", "outcome"}))
+	breakerMetrics.duration = registerOrReuse(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: reg.namespace,
+		Subsystem: reg.subsystem,
+		Name:      "circuit_breaker_call_duration_seconds",
+		Help:      "Duration of calls through the circuit breaker in seconds.",
+		Buckets:   breakerMetrics.buckets,
+	}, []string{"name", "outcome"}))
+
+	return breakerMetrics
+}
+
+// registerOrReuse registers collector on reg, or returns the collector
+// already registered under the same descriptor. It panics on any other
+// registration error, like MustRegister.
+func registerOrReuse[C prometheus.Collector](reg *Registry, collector C) C {
+	err := reg.prometheus.Register(collector)
+	if err == nil {
+		return collector
+	}
+
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		if existing, ok := already.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+
+	panic(err)
+}
+
+// State returns the underlying state gauge vec.
+func (m *CircuitBreakerMetrics) State() *prometheus.GaugeVec {
+	return m.state
+}
+
+// Transitions returns the underlying transitions counter vec.
+func (m *CircuitBreakerMetrics) Transitions() *prometheus.CounterVec {
+	return m.transitions
+}
+
+// Calls returns the underlying calls counter vec.
+func (m *CircuitBreakerMetrics) Calls() *prometheus.CounterVec {
+	return m.calls
+}
+
+// CallDuration returns the underlying call duration histogram vec.
+func (m *CircuitBreakerMetrics) CallDuration() *prometheus.HistogramVec {
+	return m.duration
+}
+
+// BreakerStateObserver returns a state change callback for the breaker called
+// name, suitable for circuitbreaker.WithOnStateChange. It initializes the
+// state gauge to 0 (closed), the state of a new breaker.
+func BreakerStateObserver[S BreakerEnum](m *CircuitBreakerMetrics, name string) func(from, to S) {
+	gauge := m.state.WithLabelValues(name)
+	gauge.Set(0)
+
+	return func(from, to S) {
+		gauge.Set(float64(to))
+		m.transitions.WithLabelValues(name, from.String(), to.String()).Inc()
+	}
+}
+
+// BreakerCallObserver returns a call callback for the breaker called name,
+// suitable for circuitbreaker.WithOnCall. Rejected calls are counted but not
+// observed in the duration histogram.
+func BreakerCallObserver[O BreakerEnum](m *CircuitBreakerMetrics, name string) func(outcome O, elapsed time.Duration) {
+	return func(outcome O, elapsed time.Duration) {
+		label := outcome.String()
+		m.calls.WithLabelValues(name, label).Inc()
+
+		if label != rejectedOutcome {
+			m.duration.WithLabelValues(name, label).Observe(elapsed.Seconds())
+		}
+	}
+}
+
+// GroupStateObserver returns a state change callback for every breaker of a
+// circuitbreaker.Group, suitable for circuitbreaker.WithGroupOnStateChange.
+// Breakers are labelled with their key, which is also their name. A key's
+// state gauge appears at its first transition.
+func GroupStateObserver[K comparable, S BreakerEnum](m *CircuitBreakerMetrics) func(key K, from, to S) {
+	return func(key K, from, to S) {
+		name := fmt.Sprint(key)
+		m.state.WithLabelValues(name).Set(float64(to))
+		m.transitions.WithLabelValues(name, from.String(), to.String()).Inc()
+	}
+}
+
+// GroupCallObserver returns a call callback for every breaker of a
+// circuitbreaker.Group, suitable for circuitbreaker.WithGroupOnCall.
+// Breakers are labelled with their key, which is also their name.
+func GroupCallObserver[K comparable, O BreakerEnum](m *CircuitBreakerMetrics) func(key K, outcome O, elapsed time.Duration) {
+	return func(key K, outcome O, elapsed time.Duration) {
+		name := fmt.Sprint(key)
+		label := outcome.String()
+		m.calls.WithLabelValues(name, label).Inc()
+
+		if label != rejectedOutcome {
+			m.duration.WithLabelValues(name, label).Observe(elapsed.Seconds())
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/metrics"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// breakerState and breakerOutcome mirror circuitbreaker.State and
+// circuitbreaker.Outcome without importing the circuitbreaker module.
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+func (s breakerState) String() string {
+	return [...]string{"closed", "open", "half-open"}[s]
+}
+
+type breakerOutcome int
+
+const (
+	outcomeSuccess breakerOutcome = iota
+	outcomeFailure
+	outcomeIgnored
+	outcomeRejected
+)
+
+func (o breakerOutcome) String() string {
+	return [...]string{"success", "failure", "ignored", "rejected"}[o]
+}
+
+// metricWithLabels returns the metric in family whose labels include all of want.
+func metricWithLabels(family *dto.MetricFamily, want map[string]string) *dto.Metric {
+	for _, metric := range family.GetMetric() {
+		labels := labelPairs(metric)
+		matched := true
+		for name, value := range want {
+			if labels[name] != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return metric
+		}
+	}
+
+	return nil
+}
+
+func TestNewCircuitBreakerMetrics(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New(metrics.WithNamespace("app"))
+	m := metrics.NewCircuitBreakerMetrics(reg)
+
+	assert.NotNil(t, m.State())
+	assert.NotNil(t, m.Transitions())
+	assert.NotNil(t, m.Calls())
+	assert.NotNil(t, m.CallDuration())
+}
+
+func TestBreakerStateObserver(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New()
+	m := metrics.NewCircuitBreakerMetrics(reg)
+
+	payments := metrics.BreakerStateObserver[breakerState](m, "payments")
+
+	payments(stateClosed, stateOpen)
+	payments(stateOpen, stateHalfOpen)
+
+	// A breaker without transitions is reported as closed.
+	metrics.BreakerStateObserver[breakerState](m, "search")
+
+	families := collectMetricFamilies(t, reg)
+
+	stateFam := findFamily(families, "circuit_breaker_state")
+	require.NotNil(t, stateFam)
+	assert.Len(t, stateFam.GetMetric(), 2)
+
+	paymentsState := metricWithLabels(stateFam, map[string]string{"name": "payments"})
+	require.NotNil(t, paymentsState)
+	assert.InDelta(t, float64(stateHalfOpen), paymentsState.GetGauge().GetValue(), 0.001)
+
+	searchState := metricWithLabels(stateFam, map[string]string{"name": "search"})
+	require.NotNil(t, searchState)
+	assert.InDelta(t, float64(stateClosed), searchState.GetGauge().GetValue(), 0.001)
+
+	transitionsFam := findFamily(families, "circuit_breaker_transitions_total")
+	require.NotNil(t, transitionsFam)
+
+	opened := metricWithLabels(transitionsFam, map[string]string{"name": "payments", "from": "closed", "to": "open"})
+	require.NotNil(t, opened)
+	assert.InDelta(t, 1.0, opened.GetCounter().GetValue(), 0.001)
+}
+
+func TestBreakerCallObserver(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New()
+	m := metrics.NewCircuitBreakerMetrics(reg)
+	observe := metrics.BreakerCallObserver[breakerOutcome](m, "payments")
+
+	observe(outcomeSuccess, 10*time.Millisecond)
+	observe(outcomeSuccess, 20*time.Millisecond)
+	observe(outcomeFailure, 30*time.Millisecond)
+	observe(outcomeIgnored, time.Millisecond)
+	observe(outcomeRejected, 0)
+
+	families := collectMetricFamilies(t, reg)
+
+	callsFam := findFamily(families, "circuit_breaker_calls_total")
+	require.NotNil(t, callsFam)
+
+	for outcome, want := range map[string]float64{"success": 2, "failure": 1, "ignored": 1, "rejected": 1} {
+		metric := metricWithLabels(callsFam, map[string]string{"name": "payments", "outcome": outcome})
+		require.NotNil(t, metric, outcome)
+		assert.InDelta(t, want, metric.GetCounter().GetValue(), 0.001, outcome)
+	}
+
+	durationFam := findFamily(families, "circuit_breaker_call_duration_seconds")
+	require.NotNil(t, durationFam)
+	assert.Nil(t, metricWithLabels(durationFam, map[string]string{"outcome": "rejected"}))
+
+	success := metricWithLabels(durationFam, map[string]string{"name": "payments", "outcome": "success"})
+	require.NotNil(t, success)
+	assert.Equal(t, uint64(2), success.GetHistogram().GetSampleCount())
+}
+
+func TestCircuitBreakerMetrics_ManyBreakersOneRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New()
+	m := metrics.NewCircuitBreakerMetrics(reg, metrics.WithCircuitBreakerBuckets([]float64{0.1, 1}))
+
+	assert.NotPanics(t, func() {
+		for _, name := range []string{"a", "b", "c"} {
+			metrics.BreakerStateObserver[breakerState](m, name)(stateClosed, stateOpen)
+			metrics.BreakerCallObserver[breakerOutcome](m, name)(outcomeFailure, time.Millisecond)
+		}
+	})
+
+	families := collectMetricFamilies(t, reg)
+
+	durationFam := findFamily(families, "circuit_breaker_call_duration_seconds")
+	require.NotNil(t, durationFam)
+	assert.Len(t, durationFam.GetMetric(), 3)
+	assert.Len(t, durationFam.GetMetric()[0].GetHistogram().GetBucket(), 2)
+}
+
+func TestNewCircuitBreakerMetrics_TwicePerRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New(metrics.WithNamespace("app"))
+	first := metrics.NewCircuitBreakerMetrics(reg)
+
+	var second *metrics.CircuitBreakerMetrics
+	require.NotPanics(t, func() {
+		second = metrics.NewCircuitBreakerMetrics(reg)
+	})
+
+	metrics.BreakerCallObserver[breakerOutcome](first, "payments")(outcomeSuccess, time.Millisecond)
+	metrics.BreakerCallObserver[breakerOutcome](second, "search")(outcomeSuccess, time.Millisecond)
+
+	assert.Same(t, first.Calls(), second.Calls())
+
+	callsFam := findFamily(collectMetricFamilies(t, reg), "app_circuit_breaker_calls_total")
+	require.NotNil(t, callsFam)
+	assert.Len(t, callsFam.GetMetric(), 2)
+}
+
+func TestGroupObservers(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.New()
+	m := metrics.NewCircuitBreakerMetrics(reg)
+
+	onStateChange := metrics.GroupStateObserver[string, breakerState](m)
+	onCall := metrics.GroupCallObserver[string, breakerOutcome](m)
+
+	onCall("api.example.com", outcomeFailure, 10*time.Millisecond)
+	onStateChange("api.example.com", stateClosed, stateOpen)
+	onCall("api.example.com", outcomeRejected, 0)
+	onCall("cdn.example.com", outcomeSuccess, time.Millisecond)
+
+	families := collectMetricFamilies(t, reg)
+
+	stateFam := findFamily(families, "circuit_breaker_state")
+	require.NotNil(t, stateFam)
+	state := metricWithLabels(stateFam, map[string]string{"name": "api.example.com"})
+	require.NotNil(t, state)
+	assert.InDelta(t, float64(stateOpen), state.GetGauge().GetValue(), 0.001)
+
+	callsFam := findFamily(families, "circuit_breaker_calls_total")
+	require.NotNil(t, callsFam)
+	for _, want := range []map[string]string{
+		{"name": "api.example.com", "outcome": "failure"},
+		{"name": "api.example.com", "outcome": "rejected"},
+		{"name": "cdn.example.com", "outcome": "success"},
+	} {
+		metric := metricWithLabels(callsFam, want)
+		require.NotNil(t, metric, "missing calls for %v", want)
+		assert.InDelta(t, 1.0, metric.GetCounter().GetValue(), 0.001)
+	}
+
+	durationFam := findFamily(families, "circuit_breaker_call_duration_seconds")
+	require.NotNil(t, durationFam)
+	assert.Len(t, durationFam.GetMetric(), 2)
+}
@@ -0,0 +1,54 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Attempt describes a failed attempt. It is passed to WithOnRetry and
+// recorded in RetryError.
+type Attempt struct {
+	// Number is the 1-based number of the attempt that failed.
+	Number int
+	// Err is the error the attempt returned.
+	Err error
+	// Elapsed is the time from the start of the first attempt to the end of
+	// this one.
+	Elapsed time.Duration
+	// NextDelay is how long Do waits before the next attempt; 0 if the
+	// attempt was not retried.
+	NextDelay time.Duration
+}
+
+// WithOnRetry registers a callback invoked after each failed attempt that
+// will be retried, before the delay. It runs on the calling goroutine.
+func WithOnRetry(fn func(ctx context.Context, attempt Attempt)) Option {
+	return func(cfg *config) {
+		cfg.onRetry = fn
+	}
+}
+
+type attemptKey struct{}
+
+type attemptInfo struct {
+	number int
+	last   bool
+}
+
+// AttemptFromContext returns the 1-based number of the attempt running with
+// ctx. It reports false when ctx does not come from Do.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	info, ok := ctx.Value(attemptKey{}).(attemptInfo)
+	return info.number, ok
+}
+
+// IsLastAttempt reports whether the attempt running with ctx is the last one
+// WithMaxAttempts allows.
+func IsLastAttempt(ctx context.Context) bool {
+	info, ok := ctx.Value(attemptKey{}).(attemptInfo)
+	return ok && info.last
+}
+
+func withAttempt(ctx context.Context, number int, last bool) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attemptInfo{number: number, last: last})
+}
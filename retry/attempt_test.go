@@ -0,0 +1,79 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+func TestWithOnRetry_ReportsEachRetriedAttempt(t *testing.T) {
+	t.Parallel()
+
+	var attempts []retry.Attempt
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		return errTransient
+	},
+		retry.WithMaxAttempts(3),
+		retry.WithBackoff(retry.Constant{Delay: 2 * time.Millisecond}),
+		retry.WithOnRetry(func(_ context.Context, attempt retry.Attempt) {
+			attempts = append(attempts, attempt)
+		}),
+	)
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected errTransient, got %v", err)
+	}
+
+	// The final failure is returned, not reported as a retry.
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 retries, got %d", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Number != i+1 {
+			t.Fatalf("expected attempt number %d, got %d", i+1, attempt.Number)
+		}
+		if !errors.Is(attempt.Err, errTransient) {
+			t.Fatalf("expected errTransient, got %v", attempt.Err)
+		}
+		if attempt.NextDelay != 2*time.Millisecond {
+			t.Fatalf("expected next delay 2ms, got %v", attempt.NextDelay)
+		}
+	}
+	if attempts[1].Elapsed < 2*time.Millisecond {
+		t.Fatalf("expected elapsed time to include the first delay, got %v", attempts[1].Elapsed)
+	}
+}
+
+func TestAttemptFromContext(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := retry.AttemptFromContext(context.Background()); ok {
+		t.Fatal("expected no attempt outside Do")
+	}
+
+	var (
+		numbers []int
+		last    []bool
+	)
+	_ = retry.Do(context.Background(), func(ctx context.Context) error {
+		n, ok := retry.AttemptFromContext(ctx)
+		if !ok {
+			t.Error("expected an attempt number inside Do")
+		}
+		numbers = append(numbers, n)
+		last = append(last, retry.IsLastAttempt(ctx))
+		return errTransient
+	}, retry.WithMaxAttempts(3), retry.WithDelay(time.Millisecond))
+
+	if len(numbers) != 3 || numbers[0] != 1 || numbers[1] != 2 || numbers[2] != 3 {
+		t.Fatalf("expected attempts 1, 2, 3, got %v", numbers)
+	}
+	if last[0] || last[1] || !last[2] {
+		t.Fatalf("expected only the third attempt to be last, got %v", last)
+	}
+}
@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes the delay before the next attempt. attempt is the
+// zero-based number of the attempt that just failed and prev is the delay
+// used before it (0 after the first attempt). The result is capped by
+// WithMaxDelay.
+type Backoff interface {
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// BackoffFunc adapts a function to the Backoff interface.
+type BackoffFunc func(attempt int, prev time.Duration) time.Duration
+
+// Next implements Backoff.
+func (f BackoffFunc) Next(attempt int, prev time.Duration) time.Duration {
+	return f(attempt, prev)
+}
+
+// WithBackoff sets the backoff used between attempts. It replaces the
+// backoff selected by WithStrategy, WithDelay, WithJitter and
+// WithJitterFraction, whatever their order.
+func WithBackoff(b Backoff) Option {
+	return func(cfg *config) {
+		cfg.backoff = b
+	}
+}
+
+// Constant waits Delay between attempts.
+type Constant struct {
+	Delay time.Duration
+}
+
+// Next implements Backoff.
+func (b Constant) Next(int, time.Duration) time.Duration {
+	return b.Delay
+}
+
+// Linear waits Delay * (attempt+1).
+type Linear struct {
+	Delay time.Duration
+}
+
+// Next implements Backoff.
+func (b Linear) Next(attempt int, _ time.Duration) time.Duration {
+	return scale(b.Delay, float64(attempt+1))
+}
+
+// Exponential waits Delay * 2^attempt.
+type Exponential struct {
+	Delay time.Duration
+}
+
+// Next implements Backoff.
+func (b Exponential) Next(attempt int, _ time.Duration) time.Duration {
+	return scale(b.Delay, math.Pow(2, float64(attempt)))
+}
+
+// Fibonacci waits Delay * fib(attempt+1): 1, 1, 2, 3, 5, 8... times Delay.
+// It grows more slowly than Exponential.
+type Fibonacci struct {
+	Delay time.Duration
+}
+
+// Next implements Backoff.
+func (b Fibonacci) Next(attempt int, _ time.Duration) time.Duration {
+	prev, cur := 0.0, 1.0
+	for range attempt {
+		prev, cur = cur, prev+cur
+	}
+
+	return scale(b.Delay, cur)
+}
+
+// FullJitter waits a random delay in [0, min(Cap, Base * 2^attempt)), as in
+// AWS's "Exponential Backoff And Jitter". It spreads retries from many
+// clients the most. A zero Cap means no cap besides WithMaxDelay.
+type FullJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+}
+
+// Next implements Backoff.
+func (b FullJitter) Next(attempt int, _ time.Duration) time.Duration {
+	return randomBetween(0, capped(Exponential{Delay: b.Base}.Next(attempt, 0), b.Cap))
+}
+
+// EqualJitter waits half of min(Cap, Base * 2^attempt) plus a random delay
+// up to the other half, so it never retries immediately.
+// A zero Cap means no cap besides WithMaxDelay.
+type EqualJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+}
+
+// Next implements Backoff.
+func (b EqualJitter) Next(attempt int, _ time.Duration) time.Duration {
+	half := capped(Exponential{Delay: b.Base}.Next(attempt, 0), b.Cap) / 2
+	return half + randomBetween(0, half)
+}
+
+// DecorrelatedJitter waits a random delay in [Base, prev * 3), capped by Cap,
+// so each delay depends on the previous one rather than the attempt number.
+// A zero Cap means no cap besides WithMaxDelay.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+}
+
+// Next implements Backoff.
+func (b DecorrelatedJitter) Next(_ int, prev time.Duration) time.Duration {
+	upper := scale(max(prev, b.Base), 3)
+	return capped(randomBetween(b.Base, upper), b.Cap)
+}
+
+// Jitter randomly moves the delay of Backoff by up to ±Fraction of it.
+type Jitter struct {
+	Backoff  Backoff
+	Fraction float64
+}
+
+// Next implements Backoff.
+func (b Jitter) Next(attempt int, prev time.Duration) time.Duration {
+	factor := 1 + (rand.Float64()*2-1)*b.Fraction //nolint:gosec // jitter does not need crypto rand
+	return scale(b.Backoff.Next(attempt, prev), factor)
+}
+
+// strategyBackoff returns the Backoff selected by WithStrategy, WithDelay,
+// WithJitter and WithJitterFraction.
+//
+//nolint:ireturn // strategies are interchangeable Backoff implementations
+func strategyBackoff(cfg *config) Backoff {
+	var b Backoff = Exponential{Delay: cfg.delay}
+
+	switch cfg.strategy {
+	case StrategyConstant:
+		b = Constant{Delay: cfg.delay}
+	case StrategyLinear:
+		b = Linear{Delay: cfg.delay}
+	case StrategyFibonacci:
+		b = Fibonacci{Delay: cfg.delay}
+	case StrategyExponential:
+	}
+
+	if cfg.jitter {
+		b = Jitter{Backoff: b, Fraction: cfg.jitterFrac}
+	}
+
+	return b
+}
+
+// scale multiplies d by factor, saturating instead of overflowing.
+func scale(d time.Duration, factor float64) time.Duration {
+	scaled := float64(d) * factor
+	if scaled >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(scaled)
+}
+
+// capped limits d to limit; a zero limit disables the cap.
+func capped(d, limit time.Duration) time.Duration {
+	if limit > 0 && d > limit {
+		return limit
+	}
+
+	return d
+}
+
+// randomBetween returns a random duration in [lower, upper).
+func randomBetween(lower, upper time.Duration) time.Duration {
+	if upper <= lower {
+		return lower
+	}
+
+	return lower + time.Duration(rand.Int64N(int64(upper-lower))) //nolint:gosec // jitter does not need crypto rand
+}
@@ -0,0 +1,136 @@
+package retry_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+func TestBackoff_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	const base = 10 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		backoff retry.Backoff
+		want    []time.Duration
+	}{
+		{"Constant", retry.Constant{Delay: base}, []time.Duration{base, base, base, base, base}},
+		{"Linear", retry.Linear{Delay: base}, []time.Duration{base, 2 * base, 3 * base, 4 * base, 5 * base}},
+		{"Exponential", retry.Exponential{Delay: base}, []time.Duration{base, 2 * base, 4 * base, 8 * base, 16 * base}},
+		{"Fibonacci", retry.Fibonacci{Delay: base}, []time.Duration{base, base, 2 * base, 3 * base, 5 * base}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for attempt, want := range tt.want {
+				if got := tt.backoff.Next(attempt, 0); got != want {
+					t.Fatalf("attempt %d: expected %v, got %v", attempt, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff_JitterBounds(t *testing.T) {
+	t.Parallel()
+
+	const (
+		base  = 10 * time.Millisecond
+		limit = 50 * time.Millisecond
+	)
+
+	t.Run("FullJitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.FullJitter{Base: base, Cap: limit}
+		for attempt := range 10 {
+			upper := min(base<<attempt, limit)
+			for range 100 {
+				if got := b.Next(attempt, 0); got < 0 || got >= upper {
+					t.Fatalf("attempt %d: expected [0, %v), got %v", attempt, upper, got)
+				}
+			}
+		}
+	})
+
+	t.Run("EqualJitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.EqualJitter{Base: base, Cap: limit}
+		for attempt := range 10 {
+			upper := min(base<<attempt, limit)
+			for range 100 {
+				if got := b.Next(attempt, 0); got < upper/2 || got > upper {
+					t.Fatalf("attempt %d: expected [%v, %v], got %v", attempt, upper/2, upper, got)
+				}
+			}
+		}
+	})
+
+	t.Run("DecorrelatedJitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.DecorrelatedJitter{Base: base, Cap: limit}
+		prev := time.Duration(0)
+		for attempt := range 100 {
+			got := b.Next(attempt, prev)
+			if got < base || got > limit || got > 3*max(prev, base) {
+				t.Fatalf("attempt %d after %v: got %v out of bounds", attempt, prev, got)
+			}
+			prev = got
+		}
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.Jitter{Backoff: retry.Constant{Delay: 100 * time.Millisecond}, Fraction: 0.25}
+		for range 100 {
+			if got := b.Next(0, 0); got < 75*time.Millisecond || got > 125*time.Millisecond {
+				t.Fatalf("expected 100ms ±25%%, got %v", got)
+			}
+		}
+	})
+}
+
+func TestBackoff_SaturatesInsteadOfOverflowing(t *testing.T) {
+	t.Parallel()
+
+	if got := (retry.Exponential{Delay: time.Second}).Next(100, 0); got != math.MaxInt64 {
+		t.Fatalf("expected saturation, got %v", got)
+	}
+	if got := (retry.FullJitter{Base: time.Second}).Next(100, 0); got < 0 {
+		t.Fatalf("expected a non-negative delay, got %v", got)
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	var seen []time.Duration
+	backoff := retry.BackoffFunc(func(attempt int, prev time.Duration) time.Duration {
+		seen = append(seen, prev)
+		return time.Duration(attempt+1) * time.Millisecond
+	})
+
+	_ = retry.Do(context.Background(), func(_ context.Context) error {
+		return errTransient
+	}, retry.WithMaxAttempts(4), retry.WithBackoff(backoff))
+
+	want := []time.Duration{0, time.Millisecond, 2 * time.Millisecond}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %d backoff calls, got %d", len(want), len(seen))
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("call %d: expected prev %v, got %v", i, want[i], seen[i])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is the RetryError cause when a retry is refused because
+// the shared Budget is spent.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+const (
+	defaultBudgetRatio        = 0.1
+	defaultBudgetMinPerSecond = 10
+	defaultBudgetWindow       = 10 * time.Second
+	budgetBuckets             = 10
+)
+
+// Budget limits retries across many Do calls, as in the gRPC and Finagle
+// retry budgets. Over a rolling window, retries are allowed while
+//
+//	retries < ratio * requests + minPerSecond * window
+//
+// so a healthy service retries freely but an outage cannot multiply load on
+// the failing dependency. A Budget is safe for concurrent use and is meant to
+// be shared, for example per dependency.
+type Budget struct {
+	mu           sync.Mutex
+	ratio        float64
+	minPerSecond float64
+	bucketWidth  time.Duration
+	buckets      []budgetBucket   // ring of consecutive time slices covering the window
+	nowFunc      func() time.Time // injectable clock for testing
+}
+
+type budgetBucket struct {
+	start    time.Time
+	requests float64
+	retries  float64
+}
+
+// BudgetOption configures a Budget.
+type BudgetOption func(*Budget)
+
+// WithRetryRatio sets the fraction of requests that may be retried.
+// Default: 0.1.
+func WithRetryRatio(ratio float64) BudgetOption {
+	return func(b *Budget) {
+		if ratio >= 0 {
+			b.ratio = ratio
+		}
+	}
+}
+
+// WithMinRetriesPerSecond sets the retry rate always allowed, so that
+// low-traffic callers can still retry.
+// Default: 10.
+func WithMinRetriesPerSecond(n float64) BudgetOption {
+	return func(b *Budget) {
+		if n >= 0 {
+			b.minPerSecond = n
+		}
+	}
+}
+
+// WithBudgetWindow sets the rolling window over which requests and retries
+// are tracked. Windows shorter than 10ns are rounded up to 10ns.
+// Default: 10s.
+func WithBudgetWindow(d time.Duration) BudgetOption {
+	return func(b *Budget) {
+		if d > 0 {
+			b.bucketWidth = max(d/budgetBuckets, 1)
+		}
+	}
+}
+
+// WithBudget makes Do draw its retries from budget. Every Do call counts as a
+// request; when a retry is refused Do fails fast with a *RetryError whose
+// cause is ErrBudgetExhausted.
+// Default: no budget.
+func WithBudget(budget *Budget) Option {
+	return func(cfg *config) {
+		cfg.budget = budget
+	}
+}
+
+// NewBudget creates a Budget with the given options.
+func NewBudget(opts ...BudgetOption) *Budget {
+	b := &Budget{
+		ratio:        defaultBudgetRatio,
+		minPerSecond: defaultBudgetMinPerSecond,
+		bucketWidth:  defaultBudgetWindow / budgetBuckets,
+		buckets:      make([]budgetBucket, budgetBuckets),
+		nowFunc:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Stats returns the requests and retries tracked over the current window.
+func (b *Budget) Stats() (requests, retries float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current(b.nowFunc())
+	return b.totals()
+}
+
+// request records a Do call.
+func (b *Budget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current(b.nowFunc()).requests++
+}
+
+// withdraw reports whether a retry is allowed and records it if so.
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket := b.current(b.nowFunc())
+	requests, retries := b.totals()
+	window := b.bucketWidth * time.Duration(len(b.buckets))
+
+	if retries >= b.ratio*requests+b.minPerSecond*window.Seconds() {
+		return false
+	}
+
+	bucket.retries++
+
+	return true
+}
+
+// totals sums the buckets that are still inside the window. Callers must hold mu.
+func (b *Budget) totals() (requests, retries float64) {
+	for _, bucket := range b.buckets {
+		requests += bucket.requests
+		retries += bucket.retries
+	}
+
+	return requests, retries
+}
+
+// current returns the bucket for now, clearing buckets that fell out of the
+// window. Callers must hold mu.
+func (b *Budget) current(now time.Time) *budgetBucket {
+	start := now.Truncate(b.bucketWidth)
+	window := b.bucketWidth * time.Duration(len(b.buckets))
+
+	for i := range b.buckets {
+		if now.Sub(b.buckets[i].start) >= window {
+			b.buckets[i] = budgetBucket{}
+		}
+	}
+
+	idx := int(start.UnixNano()/int64(b.bucketWidth)) % len(b.buckets)
+	bucket := &b.buckets[idx]
+	if !bucket.start.Equal(start) {
+		*bucket = budgetBucket{start: start}
+	}
+
+	return bucket
+}
@@ -0,0 +1,108 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+func TestBudget_FailsFastWhenSpent(t *testing.T) {
+	t.Parallel()
+
+	budget := retry.NewBudget(
+		retry.WithRetryRatio(0.5),
+		retry.WithMinRetriesPerSecond(0),
+		retry.WithBudgetWindow(time.Minute),
+	)
+
+	failing := func(_ context.Context) error { return errTransient }
+	opts := []retry.Option{retry.WithMaxAttempts(3), retry.WithDelay(time.Millisecond), retry.WithBudget(budget)}
+
+	// Two calls earn one retry: the first call spends it, the second fails fast.
+	_ = retry.Do(context.Background(), func(_ context.Context) error { return nil }, opts...)
+
+	calls := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return failing(ctx)
+	}, opts...)
+
+	if !errors.Is(err, retry.ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected error to keep errTransient, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls (one retry), got %d", calls)
+	}
+
+	requests, retries := budget.Stats()
+	if requests != 2 || retries != 1 {
+		t.Fatalf("expected 2 requests and 1 retry, got %v/%v", requests, retries)
+	}
+}
+
+func TestBudget_MinRetriesPerSecond(t *testing.T) {
+	t.Parallel()
+
+	budget := retry.NewBudget(
+		retry.WithRetryRatio(0),
+		retry.WithMinRetriesPerSecond(1),
+		retry.WithBudgetWindow(2*time.Second),
+	)
+
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		return errTransient
+	}, retry.WithMaxAttempts(10), retry.WithDelay(time.Millisecond), retry.WithBudget(budget))
+
+	if !errors.Is(err, retry.ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", err)
+	}
+	// 1 retry/s over a 2s window allows 2 retries without any traffic.
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestBudget_SharedAcrossCalls(t *testing.T) {
+	t.Parallel()
+
+	budget := retry.NewBudget(retry.WithRetryRatio(0.2), retry.WithMinRetriesPerSecond(0))
+
+	total := 0
+	for range 10 {
+		_ = retry.Do(context.Background(), func(_ context.Context) error {
+			total++
+			return errTransient
+		}, retry.WithMaxAttempts(3), retry.WithDelay(time.Millisecond), retry.WithBudget(budget))
+	}
+
+	// Without a budget, 10 failing calls would make 30 attempts.
+	if total > 13 {
+		t.Fatalf("expected retries to stay near 20%% of requests, got %d attempts", total)
+	}
+}
+
+func TestBudget_TinyWindow(t *testing.T) {
+	t.Parallel()
+
+	for _, window := range []time.Duration{time.Nanosecond, 9 * time.Nanosecond} {
+		budget := retry.NewBudget(retry.WithBudgetWindow(window))
+
+		err := retry.Do(context.Background(), func(_ context.Context) error {
+			return nil
+		}, retry.WithBudget(budget))
+		if err != nil {
+			t.Fatalf("window %v: expected no error, got %v", window, err)
+		}
+		if requests, _ := budget.Stats(); requests > 1 {
+			t.Fatalf("window %v: expected at most 1 tracked request, got %v", window, requests)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package retry
+
+import "time"
+
+// Clock is the source of time used by Do and Hedge. Tests can pass a fake
+// clock with WithClock or WithHedgeClock to check delays without sleeping;
+// see package retrytest.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single-shot timer created by a Clock.
+type Timer interface {
+	// C returns the channel the current time is sent on when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports whether the timer was
+	// still pending.
+	Stop() bool
+}
+
+// WithClock sets the clock Do uses to measure elapsed time and wait between
+// attempts. WithAttemptTimeout deadlines still use the real clock, since they
+// are enforced by the context.
+// Default: the system clock.
+func WithClock(clock Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = clock
+	}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+//nolint:ireturn // Clock implementations return their own Timer
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{timer: time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
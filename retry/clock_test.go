@@ -0,0 +1,100 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+	"github.com/GabrielNunesIT/go-libs/retry/retrytest"
+)
+
+func TestWithClock_DelaysEndToEnd(t *testing.T) {
+	t.Parallel()
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	done := make(chan error, 1)
+
+	go func() {
+		done <- retry.Do(context.Background(), func(_ context.Context) error {
+			return errTransient
+		},
+			retry.WithClock(clock),
+			retry.WithMaxAttempts(4),
+			retry.WithDelay(100*time.Millisecond),
+			retry.WithJitter(false),
+		)
+	}()
+
+	for _, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		clock.BlockUntil(1)
+
+		got, ok := clock.NextDeadline()
+		if !ok || got != want {
+			t.Fatalf("expected a %v delay, got %v (pending %v)", want, got, ok)
+		}
+		clock.Advance(got)
+	}
+
+	err := <-done
+
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %v", err)
+	}
+	if elapsed := retryErr.Attempts[3].Elapsed; elapsed != 700*time.Millisecond {
+		t.Fatalf("expected 700ms of fake elapsed time, got %v", elapsed)
+	}
+}
+
+func TestWithClock_MaxElapsedTime(t *testing.T) {
+	t.Parallel()
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	done := make(chan error, 1)
+
+	go func() {
+		done <- retry.Do(context.Background(), func(_ context.Context) error {
+			return errTransient
+		},
+			retry.WithClock(clock),
+			retry.WithMaxAttempts(10),
+			retry.WithBackoff(retry.Constant{Delay: time.Second}),
+			retry.WithMaxElapsedTime(2500*time.Millisecond),
+		)
+	}()
+
+	for range 2 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+
+	if err := <-done; !errors.Is(err, retry.ErrMaxElapsedTime) {
+		t.Fatalf("expected ErrMaxElapsedTime, got %v", err)
+	}
+}
+
+func TestWithClock_StopsTimerOnCancel(t *testing.T) {
+	t.Parallel()
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- retry.Do(ctx, func(_ context.Context) error {
+			return errTransient
+		}, retry.WithClock(clock), retry.WithDelay(time.Hour))
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("expected the timer to be stopped, got %d pending", n)
+	}
+}
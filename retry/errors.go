@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetryError is returned by Do when it gives up. It records every failed
+// attempt and unwraps to all of their errors and to Cause, so errors.Is and
+// errors.As match any of them.
+type RetryError struct {
+	// Attempts holds every failed attempt in order.
+	Attempts []Attempt
+	// Cause is why Do stopped before running out of attempts, such as the
+	// context's error, ErrMaxElapsedTime or ErrBudgetExhausted. It is nil when the attempts ran
+	// out or the last error was not retryable.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *RetryError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("retry: ")
+	if e.Cause != nil {
+		fmt.Fprintf(&b, "%v after ", e.Cause)
+	}
+
+	fmt.Fprintf(&b, "%d failed attempt", len(e.Attempts))
+	if len(e.Attempts) != 1 {
+		b.WriteString("s")
+	}
+
+	if n := len(e.Attempts); n > 0 {
+		fmt.Fprintf(&b, " in %s", e.Attempts[n-1].Elapsed.Round(time.Millisecond))
+	}
+
+	for i, attempt := range e.Attempts {
+		sep := "; "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s#%d: %v", sep, attempt.Number, attempt.Err)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the error of every attempt followed by Cause.
+func (e *RetryError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts)+1)
+	for _, attempt := range e.Attempts {
+		errs = append(errs, attempt.Err)
+	}
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+
+	return errs
+}
+
+// Last returns the error of the last attempt, or nil if there was none.
+func (e *RetryError) Last() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// PermanentError marks an error that must not be retried. Do stops as soon
+// as fn returns it; it unwraps to the original cause.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps err so that Do stops retrying immediately.
+// Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{Err: err}
+}
+
+// Error implements the error interface.
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original cause.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable is implemented by errors that know whether they are transient,
+// such as net.Error. Do stops retrying errors whose Temporary method reports
+// false.
+type Retryable interface {
+	Temporary() bool
+}
+
+// WithRetryIf sets a predicate deciding whether an error is retried. It is
+// consulted after Permanent and Retryable, so it cannot make a permanent
+// error retryable.
+// Default: retry every error.
+func WithRetryIf(fn func(err error) bool) Option {
+	return func(cfg *config) {
+		cfg.retryIf = fn
+	}
+}
+
+// shouldRetry reports whether err may be retried under cfg.
+func shouldRetry(cfg *config, err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	var retryable Retryable
+	if errors.As(err, &retryable) && !retryable.Temporary() {
+		return false
+	}
+
+	if cfg.retryIf != nil {
+		return cfg.retryIf(err)
+	}
+
+	return true
+}
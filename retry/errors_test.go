@@ -0,0 +1,171 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+var errValidation = errors.New("invalid request")
+
+type temporaryError struct {
+	temporary bool
+}
+
+func (e temporaryError) Error() string   { return "temporary error" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+func TestPermanent_StopsRetrying(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		return retry.Permanent(errValidation)
+	}, retry.WithMaxAttempts(5), retry.WithDelay(time.Millisecond))
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+	if !errors.Is(err, errValidation) {
+		t.Fatalf("expected error to unwrap to errValidation, got %v", err)
+	}
+
+	var permanent *retry.PermanentError
+	if !errors.As(err, &permanent) {
+		t.Fatalf("expected *PermanentError, got %T", err)
+	}
+	if permanent.Error() != errValidation.Error() {
+		t.Fatalf("expected message %q, got %q", errValidation.Error(), permanent.Error())
+	}
+}
+
+func TestPermanent_Nil(t *testing.T) {
+	t.Parallel()
+
+	if err := retry.Permanent(nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestWithRetryIf(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		if calls == 1 {
+			return errTransient
+		}
+		return errValidation
+	},
+		retry.WithMaxAttempts(5),
+		retry.WithDelay(time.Millisecond),
+		retry.WithRetryIf(func(err error) bool { return errors.Is(err, errTransient) }),
+	)
+
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	if !errors.Is(err, errValidation) {
+		t.Fatalf("expected errValidation, got %v", err)
+	}
+}
+
+func TestRetryable_RespectsTemporary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		temporary bool
+		want      int
+	}{
+		{"Temporary", true, 3},
+		{"NotTemporary", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			err := retry.Do(context.Background(), func(_ context.Context) error {
+				calls++
+				return temporaryError{temporary: tt.temporary}
+			}, retry.WithMaxAttempts(3), retry.WithDelay(time.Millisecond))
+
+			if calls != tt.want {
+				t.Fatalf("expected %d calls, got %d", tt.want, calls)
+			}
+
+			var got temporaryError
+			if !errors.As(err, &got) {
+				t.Fatalf("expected temporaryError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryError_RecordsEveryAttempt(t *testing.T) {
+	t.Parallel()
+
+	errFirst := errors.New("first failure")
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		if calls == 1 {
+			return errFirst
+		}
+		return errTransient
+	}, retry.WithMaxAttempts(3), retry.WithBackoff(retry.Constant{Delay: time.Millisecond}))
+
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %T", err)
+	}
+	if len(retryErr.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(retryErr.Attempts))
+	}
+	if retryErr.Cause != nil {
+		t.Fatalf("expected no cause, got %v", retryErr.Cause)
+	}
+	if !errors.Is(err, errFirst) || !errors.Is(err, errTransient) {
+		t.Fatalf("expected error to match every attempt, got %v", err)
+	}
+	if !errors.Is(retryErr.Last(), errTransient) {
+		t.Fatalf("expected last error errTransient, got %v", retryErr.Last())
+	}
+	if retryErr.Attempts[0].NextDelay != time.Millisecond || retryErr.Attempts[2].NextDelay != 0 {
+		t.Fatalf("unexpected delays: %+v", retryErr.Attempts)
+	}
+
+	want := "#1: first failure; #2: transient failure; #3: transient failure"
+	if msg := err.Error(); !strings.HasPrefix(msg, "retry: 3 failed attempts in ") || !strings.HasSuffix(msg, want) {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestRetryError_CancellationKeepsCause(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := retry.Do(ctx, func(_ context.Context) error {
+		cancel()
+		return errTransient
+	}, retry.WithDelay(time.Second))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected cancellation to keep errTransient, got %v", err)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "retry: context canceled after 1 failed attempt") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
@@ -0,0 +1,147 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultHedgeDelay = 50 * time.Millisecond
+	defaultMaxHedges  = 1
+)
+
+type hedgeConfig struct {
+	delay     time.Duration
+	maxHedges int
+	clock     Clock
+}
+
+// HedgeOption configures Hedge.
+type HedgeOption func(*hedgeConfig)
+
+// WithHedgeDelay sets how long Hedge waits for an attempt before starting
+// the next one. Default: 50ms.
+func WithHedgeDelay(d time.Duration) HedgeOption {
+	return func(cfg *hedgeConfig) {
+		if d >= 0 {
+			cfg.delay = d
+		}
+	}
+}
+
+// WithMaxHedges sets how many speculative attempts Hedge may start in
+// addition to the first one. Default: 1.
+func WithMaxHedges(n int) HedgeOption {
+	return func(cfg *hedgeConfig) {
+		if n >= 0 {
+			cfg.maxHedges = n
+		}
+	}
+}
+
+// WithHedgeClock sets the clock Hedge uses to wait for the hedge delay and
+// to measure latency. Default: the system clock.
+func WithHedgeClock(clock Clock) HedgeOption {
+	return func(cfg *hedgeConfig) {
+		cfg.clock = clock
+	}
+}
+
+// HedgeStats describes how a Hedge call went.
+type HedgeStats struct {
+	// Attempts is the number of attempts started.
+	Attempts int
+	// Winner is the 1-based number of the attempt that succeeded; 0 if none did.
+	Winner int
+	// Latency is the time until Hedge returned.
+	Latency time.Duration
+}
+
+type hedgeResult[T any] struct {
+	number  int
+	value   T
+	err     error
+	elapsed time.Duration
+}
+
+// Hedge runs fn and, if it has not answered within the hedge delay, starts
+// another attempt concurrently, up to WithMaxHedges extra attempts. An attempt
+// that fails starts the next one right away. The first success wins and the
+// other attempts are cancelled through their contexts, so fn must be safe to
+// run concurrently and should be idempotent. fn can read its attempt number
+// with AttemptFromContext.
+//
+// Hedge returns an error only when every attempt fails or ctx is done; the
+// error is a *RetryError holding every attempt's error.
+func Hedge[T any](ctx context.Context, fn func(ctx context.Context) (T, error), opts ...HedgeOption) (T, HedgeStats, error) {
+	cfg := &hedgeConfig{
+		delay:     defaultHedgeDelay,
+		maxHedges: defaultMaxHedges,
+		clock:     systemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		zero     T
+		start    = cfg.clock.Now()
+		total    = 1 + cfg.maxHedges
+		results  = make(chan hedgeResult[T], total) // buffered so late attempts never block
+		attempts []Attempt
+		stats    HedgeStats
+	)
+
+	launch := func() {
+		stats.Attempts++
+		number := stats.Attempts
+		go func() {
+			value, err := fn(withAttempt(ctx, number, number == total))
+			results <- hedgeResult[T]{number: number, value: value, err: err, elapsed: cfg.clock.Now().Sub(start)}
+		}()
+	}
+
+	launch()
+
+	// Timer has no Reset, so each hedge gets a fresh timer.
+	timer := cfg.clock.NewTimer(cfg.delay)
+	defer func() { timer.Stop() }()
+	restart := func() {
+		timer.Stop()
+		timer = cfg.clock.NewTimer(cfg.delay)
+	}
+
+	for {
+		select {
+		case result := <-results:
+			if result.err == nil {
+				stats.Winner = result.number
+				stats.Latency = cfg.clock.Now().Sub(start)
+				return result.value, stats, nil
+			}
+
+			attempts = append(attempts, Attempt{Number: result.number, Err: result.err, Elapsed: result.elapsed})
+			if len(attempts) == total {
+				stats.Latency = cfg.clock.Now().Sub(start)
+				return zero, stats, &RetryError{Attempts: attempts}
+			}
+
+			if stats.Attempts < total {
+				launch()
+				restart()
+			}
+		case <-timer.C():
+			if stats.Attempts < total {
+				launch()
+				restart()
+			}
+		case <-ctx.Done():
+			stats.Latency = cfg.clock.Now().Sub(start)
+			return zero, stats, &RetryError{Attempts: attempts, Cause: ctx.Err()}
+		}
+	}
+}
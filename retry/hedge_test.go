@@ -0,0 +1,161 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+	"github.com/GabrielNunesIT/go-libs/retry/retrytest"
+)
+
+func TestHedge_FastFirstAttemptDoesNotHedge(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	got, stats, err := retry.Hedge(context.Background(), func(_ context.Context) (string, error) {
+		calls.Add(1)
+		return "fast", nil
+	}, retry.WithHedgeDelay(time.Second))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != "fast" {
+		t.Fatalf("expected %q, got %q", "fast", got)
+	}
+	if stats.Attempts != 1 || stats.Winner != 1 || calls.Load() != 1 {
+		t.Fatalf("expected a single winning attempt, got %+v after %d calls", stats, calls.Load())
+	}
+}
+
+func TestHedge_SlowAttemptIsHedgedAndCancelled(t *testing.T) {
+	t.Parallel()
+
+	cancelled := make(chan struct{})
+	got, stats, err := retry.Hedge(context.Background(), func(ctx context.Context) (int, error) {
+		n, _ := retry.AttemptFromContext(ctx)
+		if n == 1 {
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}, retry.WithHedgeDelay(10*time.Millisecond))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != 2 || stats.Winner != 2 || stats.Attempts != 2 {
+		t.Fatalf("expected the hedge to win, got %d with %+v", got, stats)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow attempt to be cancelled")
+	}
+}
+
+func TestHedge_WithHedgeClock(t *testing.T) {
+	t.Parallel()
+
+	type outcome struct {
+		value int
+		stats retry.HedgeStats
+		err   error
+	}
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	done := make(chan outcome, 1)
+
+	go func() {
+		value, stats, err := retry.Hedge(context.Background(), func(ctx context.Context) (int, error) {
+			n, _ := retry.AttemptFromContext(ctx)
+			if n == 1 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return n, nil
+		}, retry.WithHedgeClock(clock), retry.WithHedgeDelay(time.Second))
+		done <- outcome{value: value, stats: stats, err: err}
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	got := <-done
+
+	if got.err != nil {
+		t.Fatalf("expected no error, got %v", got.err)
+	}
+	if got.value != 2 || got.stats.Winner != 2 || got.stats.Attempts != 2 {
+		t.Fatalf("expected the hedge to win, got %d with %+v", got.value, got.stats)
+	}
+	if got.stats.Latency != time.Second {
+		t.Fatalf("expected 1s of fake latency, got %v", got.stats.Latency)
+	}
+}
+
+func TestHedge_FailureStartsNextAttemptImmediately(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	_, stats, err := retry.Hedge(context.Background(), func(ctx context.Context) (int, error) {
+		if n, _ := retry.AttemptFromContext(ctx); n == 1 {
+			return 0, errTransient
+		}
+		return 2, nil
+	}, retry.WithHedgeDelay(time.Minute))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.Winner != 2 {
+		t.Fatalf("expected the second attempt to win, got %+v", stats)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected no wait for the hedge delay, elapsed %v", elapsed)
+	}
+}
+
+func TestHedge_AllAttemptsFail(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	_, stats, err := retry.Hedge(context.Background(), func(_ context.Context) (int, error) {
+		calls.Add(1)
+		return 0, errTransient
+	}, retry.WithHedgeDelay(time.Millisecond), retry.WithMaxHedges(2))
+
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %v", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected errTransient, got %v", err)
+	}
+	if len(retryErr.Attempts) != 3 || calls.Load() != 3 {
+		t.Fatalf("expected 3 failed attempts, got %d after %d calls", len(retryErr.Attempts), calls.Load())
+	}
+	if stats.Winner != 0 || stats.Attempts != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHedge_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := retry.Hedge(ctx, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, retry.WithHedgeDelay(time.Minute))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Package grpc provides a gRPC client interceptor that retries calls with
+// the go-libs retry package.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// pushbackKey is the trailer a server sets to ask for a retry delay in
+	// milliseconds, or to stop retries with a negative or invalid value.
+	pushbackKey = "grpc-retry-pushback-ms"
+	// previousAttemptsKey is the metadata sent on retries with the number of
+	// preceding attempts.
+	previousAttemptsKey = "grpc-previous-rpc-attempts"
+)
+
+type config struct {
+	options    []retry.Option
+	retryCodes map[codes.Code]bool
+}
+
+// Option configures the interceptor.
+type Option func(*config)
+
+// WithRetryOptions sets the options passed to retry.Do for every call.
+func WithRetryOptions(opts ...retry.Option) Option {
+	return func(cfg *config) {
+		cfg.options = append(cfg.options, opts...)
+	}
+}
+
+// WithRetryCodes sets the status codes that are retried. Any other error is
+// returned immediately.
+// Default: Unavailable, ResourceExhausted.
+func WithRetryCodes(retryCodes ...codes.Code) Option {
+	return func(cfg *config) {
+		cfg.retryCodes = make(map[codes.Code]bool, len(retryCodes))
+		for _, code := range retryCodes {
+			cfg.retryCodes[code] = true
+		}
+	}
+}
+
+// WithPolicy applies policy's retry options and, if set, its
+// RetryableGRPCCodes. Unknown code names are skipped, so validate the policy
+// with Policy.Validate when loading it.
+func WithPolicy(policy retry.Policy) Option {
+	return func(cfg *config) {
+		cfg.options = append(cfg.options, policy.Options()...)
+
+		if len(policy.RetryableGRPCCodes) == 0 {
+			return
+		}
+
+		cfg.retryCodes = make(map[codes.Code]bool, len(policy.RetryableGRPCCodes))
+		for _, name := range policy.RetryableGRPCCodes {
+			var code codes.Code
+			if err := code.UnmarshalJSON([]byte(strconv.Quote(name))); err == nil {
+				cfg.retryCodes[code] = true
+			}
+		}
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that retries
+// unary calls failing with a retryable code. A grpc-retry-pushback-ms trailer
+// replaces the computed delay, or stops retrying when it is negative or
+// invalid. Retries carry the number of previous attempts in the
+// grpc-previous-rpc-attempts metadata.
+//
+// The interceptor returns the last attempt's status error, or the context's
+// status when the call is cancelled while waiting to retry.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := &config{
+		retryCodes: map[codes.Code]bool{
+			codes.Unavailable:       true,
+			codes.ResourceExhausted: true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		err := retry.Do(ctx, func(ctx context.Context) error {
+			if n, _ := retry.AttemptFromContext(ctx); n > 1 {
+				ctx = metadata.AppendToOutgoingContext(ctx, previousAttemptsKey, strconv.Itoa(n-1))
+			}
+
+			var trailer metadata.MD
+			err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Trailer(&trailer))...)
+
+			return cfg.classify(err, trailer)
+		}, cfg.options...)
+
+		return statusError(err)
+	}
+}
+
+// classify marks non-retryable errors as permanent and applies the server's
+// pushback.
+func (cfg *config) classify(err error, trailer metadata.MD) error {
+	if err == nil {
+		return nil
+	}
+
+	if !cfg.retryCodes[status.Code(err)] {
+		return retry.Permanent(err)
+	}
+
+	values := trailer.Get(pushbackKey)
+	if len(values) == 0 {
+		return err //nolint:wrapcheck // status errors are returned unchanged
+	}
+
+	ms, parseErr := strconv.Atoi(values[0])
+	if parseErr != nil || ms < 0 {
+		return retry.Permanent(err)
+	}
+
+	return retry.RetryAfter(err, time.Duration(ms)*time.Millisecond)
+}
+
+// statusError turns the error returned by retry.Do back into a status error.
+func statusError(err error) error {
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		return err
+	}
+
+	if errors.Is(retryErr.Cause, context.Canceled) || errors.Is(retryErr.Cause, context.DeadlineExceeded) {
+		return status.FromContextError(retryErr.Cause).Err()
+	}
+
+	last := retryErr.Last()
+
+	var permanent *retry.PermanentError
+	if errors.As(last, &permanent) {
+		last = permanent.Err
+	}
+
+	var delayed *retry.RetryAfterError
+	if errors.As(last, &delayed) {
+		last = delayed.Err
+	}
+
+	return last
+}
@@ -0,0 +1,215 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+	retrygrpc "github.com/GabrielNunesIT/go-libs/retry/integrations/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// flakyHealthServer fails the first failures calls with code, optionally
+// setting a pushback trailer, and records the attempt metadata it receives.
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	failures int64
+	code     codes.Code
+	pushback string
+
+	calls    atomic.Int64
+	mu       sync.Mutex
+	previous []string
+}
+
+func (s *flakyHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.previous = append(s.previous, md.Get("grpc-previous-rpc-attempts")...)
+	s.mu.Unlock()
+
+	if s.calls.Add(1) <= s.failures {
+		if s.pushback != "" {
+			_ = grpc.SetTrailer(ctx, metadata.Pairs("grpc-retry-pushback-ms", s.pushback))
+		}
+		return nil, status.Error(s.code, "injected failure")
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+// startServer serves srv over an in-process bufconn listener and returns a
+// client using the retry interceptor.
+func startServer(t *testing.T, srv healthpb.HealthServer, opts ...retrygrpc.Option) healthpb.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, srv)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	opts = append([]retrygrpc.Option{
+		retrygrpc.WithRetryOptions(retry.WithMaxAttempts(4), retry.WithDelay(time.Millisecond)),
+	}, opts...)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(retrygrpc.UnaryClientInterceptor(opts...)),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryClientInterceptor_RetriesUntilSuccess(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 2, code: codes.Unavailable}
+	client := startServer(t, srv)
+
+	resp, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v", resp.GetStatus())
+	}
+	if got := srv.calls.Load(); got != 3 {
+		t.Fatalf("expected 3 calls, got %d", got)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if len(srv.previous) != 2 || srv.previous[0] != "1" || srv.previous[1] != "2" {
+		t.Fatalf("expected previous attempts 1 and 2, got %v", srv.previous)
+	}
+}
+
+func TestUnaryClientInterceptor_ReturnsLastStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 10, code: codes.ResourceExhausted}
+	client := startServer(t, srv)
+
+	_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if got := srv.calls.Load(); got != 4 {
+		t.Fatalf("expected 4 calls, got %d", got)
+	}
+}
+
+func TestUnaryClientInterceptor_DoesNotRetryOtherCodes(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 10, code: codes.InvalidArgument}
+	client := startServer(t, srv)
+
+	_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Fatalf("expected 1 call, got %d", got)
+	}
+}
+
+func TestUnaryClientInterceptor_CustomCodes(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 1, code: codes.Aborted}
+	client := startServer(t, srv, retrygrpc.WithRetryCodes(codes.Aborted))
+
+	if _, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("expected Aborted to be retried, got %v", err)
+	}
+}
+
+func TestUnaryClientInterceptor_WithPolicy(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 10, code: codes.Aborted}
+	client := startServer(t, srv, retrygrpc.WithPolicy(retry.Policy{
+		MaxAttempts:        2,
+		Delay:              time.Millisecond,
+		RetryableGRPCCodes: []string{"ABORTED"},
+	}))
+
+	_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted, got %v", err)
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Fatalf("expected the policy's 2 attempts, got %d", got)
+	}
+}
+
+func TestUnaryClientInterceptor_Pushback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Delay", func(t *testing.T) {
+		t.Parallel()
+
+		srv := &flakyHealthServer{failures: 1, code: codes.Unavailable, pushback: "50"}
+		client := startServer(t, srv)
+
+		start := time.Now()
+		if _, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("expected to wait for the pushback, elapsed %v", elapsed)
+		}
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		t.Parallel()
+
+		srv := &flakyHealthServer{failures: 10, code: codes.Unavailable, pushback: "-1"}
+		client := startServer(t, srv)
+
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		if status.Code(err) != codes.Unavailable {
+			t.Fatalf("expected Unavailable, got %v", err)
+		}
+		if got := srv.calls.Load(); got != 1 {
+			t.Fatalf("expected a negative pushback to stop retries, got %d calls", got)
+		}
+	})
+}
+
+func TestUnaryClientInterceptor_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	srv := &flakyHealthServer{failures: 10, code: codes.Unavailable}
+	client := startServer(t, srv, retrygrpc.WithRetryOptions(retry.WithDelay(time.Minute)))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
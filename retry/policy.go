@@ -0,0 +1,230 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ErrInvalidPolicy is wrapped by every error returned from Policy.Validate.
+var ErrInvalidPolicy = errors.New("invalid retry policy")
+
+// Strategy names accepted by Policy.Strategy.
+const (
+	StrategyNameConstant           = "constant"
+	StrategyNameLinear             = "linear"
+	StrategyNameExponential        = "exponential"
+	StrategyNameFibonacci          = "fibonacci"
+	StrategyNameFullJitter         = "full-jitter"
+	StrategyNameEqualJitter        = "equal-jitter"
+	StrategyNameDecorrelatedJitter = "decorrelated-jitter"
+)
+
+// Policy is a reusable retry configuration that can be loaded from files or
+// the environment, for example with configloader. Durations are written as
+// strings such as "100ms" or "2s". koanf and YAML decode them, but
+// encoding/json does not: decode JSON through koanf, or write durations as
+// nanoseconds when using encoding/json directly. Zero fields keep the
+// defaults of the matching options.
+type Policy struct {
+	// MaxAttempts is the maximum number of attempts. Default: 3.
+	MaxAttempts int `koanf:"max_attempts" yaml:"max_attempts" json:"max_attempts"`
+	// Delay is the base delay between attempts. Default: 100ms.
+	Delay time.Duration `koanf:"delay" yaml:"delay" json:"delay"`
+	// MaxDelay caps each delay. Default: 30s.
+	MaxDelay time.Duration `koanf:"max_delay" yaml:"max_delay" json:"max_delay"`
+	// MaxElapsedTime limits the total time spent retrying. Default: no limit.
+	MaxElapsedTime time.Duration `koanf:"max_elapsed_time" yaml:"max_elapsed_time" json:"max_elapsed_time"`
+	// AttemptTimeout bounds each attempt. Default: no limit.
+	AttemptTimeout time.Duration `koanf:"attempt_timeout" yaml:"attempt_timeout" json:"attempt_timeout"`
+	// Strategy is one of the StrategyName constants. Default: "exponential".
+	Strategy string `koanf:"strategy" yaml:"strategy" json:"strategy"`
+	// Jitter randomly moves each delay by up to ±Jitter of it, between 0 and
+	// 1; 0 disables jitter. It is ignored by the jittered strategies.
+	// Default: 0.25.
+	Jitter *float64 `koanf:"jitter" yaml:"jitter" json:"jitter"`
+	// RetryableCodes are the HTTP status codes retried by Transport.
+	// Default: 429, 502, 503 and 504.
+	RetryableCodes []int `koanf:"retryable_codes" yaml:"retryable_codes" json:"retryable_codes"`
+	// RetryableGRPCCodes are the gRPC status codes retried by the
+	// integrations/grpc interceptor, by their canonical names such as
+	// "UNAVAILABLE". Default: UNAVAILABLE and RESOURCE_EXHAUSTED.
+	RetryableGRPCCodes []string `koanf:"retryable_grpc_codes" yaml:"retryable_grpc_codes" json:"retryable_grpc_codes"`
+}
+
+// Validate reports every invalid field, each wrapping ErrInvalidPolicy.
+func (p Policy) Validate() error {
+	var errs []error
+
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidPolicy}, args...)...))
+	}
+
+	if p.MaxAttempts < 0 {
+		invalid("max_attempts must not be negative, got %d", p.MaxAttempts)
+	}
+	if p.Delay < 0 {
+		invalid("delay must not be negative, got %s", p.Delay)
+	}
+	if p.MaxDelay < 0 {
+		invalid("max_delay must not be negative, got %s", p.MaxDelay)
+	}
+	if p.Delay > 0 && p.MaxDelay > 0 && p.MaxDelay < p.Delay {
+		invalid("max_delay %s is shorter than delay %s", p.MaxDelay, p.Delay)
+	}
+	if p.MaxElapsedTime < 0 {
+		invalid("max_elapsed_time must not be negative, got %s", p.MaxElapsedTime)
+	}
+	if p.AttemptTimeout < 0 {
+		invalid("attempt_timeout must not be negative, got %s", p.AttemptTimeout)
+	}
+	if p.Jitter != nil && (*p.Jitter < 0 || *p.Jitter > 1) {
+		invalid("jitter must be between 0 and 1, got %v", *p.Jitter)
+	}
+	if _, ok := p.strategy(); !ok {
+		invalid("unknown strategy %q", p.Strategy)
+	}
+	for _, code := range p.RetryableCodes {
+		if code < 100 || code > 599 {
+			invalid("retryable code %d is not an HTTP status code", code)
+		}
+	}
+	for _, name := range p.RetryableGRPCCodes {
+		if !slices.Contains(grpcCodeNames, name) {
+			invalid("retryable gRPC code %q is not a canonical gRPC code name", name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Options returns the options equivalent to p. Options passed after them
+// override the policy, except that the jittered strategies are applied with
+// WithBackoff, which only another WithBackoff replaces.
+func (p Policy) Options() []Option {
+	var opts []Option
+
+	if p.MaxAttempts > 0 {
+		opts = append(opts, WithMaxAttempts(p.MaxAttempts))
+	}
+	if p.Delay > 0 {
+		opts = append(opts, WithDelay(p.Delay))
+	}
+	if p.MaxDelay > 0 {
+		opts = append(opts, WithMaxDelay(p.MaxDelay))
+	}
+	if p.MaxElapsedTime > 0 {
+		opts = append(opts, WithMaxElapsedTime(p.MaxElapsedTime))
+	}
+	if p.AttemptTimeout > 0 {
+		opts = append(opts, WithAttemptTimeout(p.AttemptTimeout))
+	}
+	if p.Jitter != nil {
+		opts = append(opts, WithJitterFraction(*p.Jitter))
+	}
+	if opt, ok := p.strategy(); ok && opt != nil {
+		opts = append(opts, opt)
+	}
+
+	return opts
+}
+
+// Do runs fn with Do under p, after validating it. Options passed to Do
+// override the policy as described in Options. Use
+// DoValue(ctx, fn, p.Options()...) for functions returning a value.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	return Do(ctx, fn, append(p.Options(), opts...)...)
+}
+
+// TransportOptions returns the Transport options equivalent to p. The
+// transport ignores AttemptTimeout; bound requests with http.Client.Timeout.
+func (p Policy) TransportOptions() []TransportOption {
+	opts := []TransportOption{WithRetryOptions(p.Options()...)}
+	if len(p.RetryableCodes) > 0 {
+		opts = append(opts, WithRetryStatusCodes(p.RetryableCodes...))
+	}
+
+	return opts
+}
+
+// strategy returns the option selecting p.Strategy, nil for the default
+// strategy, and false for unknown strategies.
+func (p Policy) strategy() (Option, bool) {
+	delay := p.Delay
+	if delay == 0 {
+		delay = defaultDelay
+	}
+
+	switch p.Strategy {
+	case "":
+		return nil, true
+	case StrategyNameConstant:
+		return WithStrategy(StrategyConstant), true
+	case StrategyNameLinear:
+		return WithStrategy(StrategyLinear), true
+	case StrategyNameExponential:
+		return WithStrategy(StrategyExponential), true
+	case StrategyNameFibonacci:
+		return WithStrategy(StrategyFibonacci), true
+	case StrategyNameFullJitter:
+		return WithBackoff(FullJitter{Base: delay, Cap: p.MaxDelay}), true
+	case StrategyNameEqualJitter:
+		return WithBackoff(EqualJitter{Base: delay, Cap: p.MaxDelay}), true
+	case StrategyNameDecorrelatedJitter:
+		return WithBackoff(DecorrelatedJitter{Base: delay, Cap: p.MaxDelay}), true
+	default:
+		return nil, false
+	}
+}
+
+// grpcCodeNames are the canonical gRPC status code names accepted in
+// Policy.RetryableGRPCCodes.
+var grpcCodeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// DefaultPolicyName is the entry Policies.Get falls back to.
+const DefaultPolicyName = "default"
+
+// Policies holds named policies, such as "db" or "http-external", so that
+// each can be tuned per environment through configuration.
+type Policies map[string]Policy
+
+// Get returns the policy called name, or the "default" policy if there is no
+// such entry. Without either it returns the zero Policy, which uses the
+// option defaults.
+func (p Policies) Get(name string) Policy {
+	if policy, ok := p[name]; ok {
+		return policy
+	}
+
+	return p[DefaultPolicyName]
+}
+
+// Validate validates every policy, prefixing errors with the policy name.
+func (p Policies) Validate() error {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := p[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("policy %q: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,332 @@
+package retry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+	"github.com/GabrielNunesIT/go-libs/retry/retrytest"
+	koanfjson "github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		policy  retry.Policy
+		wantErr string
+	}{
+		{"Zero", retry.Policy{}, ""},
+		{"Full", retry.Policy{
+			MaxAttempts:        5,
+			Delay:              50 * time.Millisecond,
+			MaxDelay:           time.Second,
+			MaxElapsedTime:     10 * time.Second,
+			AttemptTimeout:     2 * time.Second,
+			Strategy:           retry.StrategyNameDecorrelatedJitter,
+			Jitter:             ptr(0.1),
+			RetryableCodes:     []int{http.StatusServiceUnavailable},
+			RetryableGRPCCodes: []string{"UNAVAILABLE", "ABORTED"},
+		}, ""},
+		{"NegativeAttempts", retry.Policy{MaxAttempts: -1}, "max_attempts must not be negative"},
+		{"MaxDelayBelowDelay", retry.Policy{Delay: time.Second, MaxDelay: time.Millisecond}, "max_delay 1ms is shorter than delay 1s"},
+		{"UnknownStrategy", retry.Policy{Strategy: "random"}, `unknown strategy "random"`},
+		{"Jitter", retry.Policy{Jitter: ptr(1.5)}, "jitter must be between 0 and 1"},
+		{"GRPCCode", retry.Policy{RetryableGRPCCodes: []string{"Unavailable"}}, `retryable gRPC code "Unavailable"`},
+		{"Code", retry.Policy{RetryableCodes: []int{42}}, "retryable code 42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected a valid policy, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, retry.ErrInvalidPolicy) {
+				t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error to contain %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestPolicy_Do(t *testing.T) {
+	t.Parallel()
+
+	policy := retry.Policy{MaxAttempts: 4, Delay: time.Millisecond, Strategy: retry.StrategyNameConstant}
+
+	calls := 0
+	err := policy.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected errTransient, got %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", calls)
+	}
+}
+
+func TestPolicy_DoRejectsInvalidPolicy(t *testing.T) {
+	t.Parallel()
+
+	err := retry.Policy{Strategy: "random"}.Do(context.Background(), func(_ context.Context) error {
+		t.Error("expected fn not to run")
+		return nil
+	})
+
+	if !errors.Is(err, retry.ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+	}
+}
+
+func TestPolicy_StrategyDelays(t *testing.T) {
+	t.Parallel()
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	policy := retry.Policy{
+		MaxAttempts: 5,
+		Delay:       10 * time.Millisecond,
+		Strategy:    retry.StrategyNameFibonacci,
+		Jitter:      ptr(0.0),
+	}
+
+	expectDelays(t, clock, policy, []time.Duration{10, 10, 20, 30}, retry.WithClock(clock))
+}
+
+func TestPolicy_DoOptionsOverridePolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := retry.Policy{
+		MaxAttempts: 3,
+		Delay:       10 * time.Millisecond,
+		Strategy:    retry.StrategyNameConstant,
+		Jitter:      ptr(0.5),
+	}
+
+	tests := []struct {
+		name string
+		opts []retry.Option
+		want []time.Duration
+	}{
+		{"Delay", []retry.Option{retry.WithDelay(time.Second), retry.WithJitter(false)}, []time.Duration{1000, 1000}},
+		{"Strategy", []retry.Option{retry.WithStrategy(retry.StrategyLinear), retry.WithJitterFraction(0)}, []time.Duration{10, 20}},
+		{"Backoff", []retry.Option{retry.WithBackoff(retry.Constant{Delay: 5 * time.Millisecond})}, []time.Duration{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := retrytest.NewFakeClock(time.Unix(0, 0))
+			expectDelays(t, clock, policy, tt.want, append(tt.opts, retry.WithClock(clock))...)
+		})
+	}
+}
+
+func TestPolicy_ZeroPolicyKeepsDefaults(t *testing.T) {
+	t.Parallel()
+
+	if opts := (retry.Policy{}).Options(); len(opts) != 0 {
+		t.Fatalf("expected the zero Policy to add no options, got %d", len(opts))
+	}
+	if opts := (retry.Policy{Jitter: ptr(0.0)}).Options(); len(opts) != 1 {
+		t.Fatalf("expected an explicit jitter of 0 to add an option, got %d", len(opts))
+	}
+}
+
+// expectDelays runs a failing fn under policy and checks each delay, in
+// milliseconds, that Do waits on clock.
+func expectDelays(t *testing.T, clock *retrytest.FakeClock, policy retry.Policy, want []time.Duration, opts ...retry.Option) {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- policy.Do(context.Background(), func(_ context.Context) error {
+			return errTransient
+		}, opts...)
+	}()
+
+	for _, ms := range want {
+		clock.BlockUntil(1)
+
+		got, _ := clock.NextDeadline()
+		if got != ms*time.Millisecond {
+			t.Fatalf("expected a %v delay, got %v", ms*time.Millisecond, got)
+		}
+		clock.Advance(got)
+	}
+
+	<-done
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPolicy_TransportOptionsReadBody(t *testing.T) {
+	t.Parallel()
+
+	// The body is still streaming when RoundTrip returns, so it is read after
+	// any attempt context would have ended.
+	payload := strings.Repeat("x", 64<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, payload)
+		w.(http.Flusher).Flush() //nolint:forcetypeassert // httptest writers implement Flusher
+		time.Sleep(20 * time.Millisecond)
+		_, _ = io.WriteString(w, payload)
+	}))
+	t.Cleanup(server.Close)
+
+	policy := retry.Policy{AttemptTimeout: 5 * time.Second, RetryableCodes: []int{http.StatusServiceUnavailable}}
+	client := &http.Client{Transport: retry.Transport(nil, policy.TransportOptions()...)}
+
+	_, body, err := send(t, client, http.MethodGet, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if len(body) != 2*len(payload) {
+		t.Fatalf("expected to read %d bytes, got %d", 2*len(payload), len(body))
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+
+	policies := retry.Policies{
+		retry.DefaultPolicyName: {MaxAttempts: 2},
+		"db":                    {MaxAttempts: 5},
+		"http-external":         {Strategy: "bogus"},
+	}
+
+	if got := policies.Get("db").MaxAttempts; got != 5 {
+		t.Fatalf("expected db policy, got %d attempts", got)
+	}
+	if got := policies.Get("cache").MaxAttempts; got != 2 {
+		t.Fatalf("expected the default policy, got %d attempts", got)
+	}
+
+	err := policies.Validate()
+	if !errors.Is(err, retry.ErrInvalidPolicy) || !strings.Contains(err.Error(), `policy "http-external"`) {
+		t.Fatalf("expected http-external to be invalid, got %v", err)
+	}
+}
+
+func TestPolicy_EncodingJSON(t *testing.T) {
+	t.Parallel()
+
+	data := `{"max_attempts": 5, "delay": 50000000, "strategy": "linear", "jitter": 0, "retryable_codes": [503]}`
+
+	var policy retry.Policy
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		t.Fatalf("failed to decode policy: %v", err)
+	}
+
+	if policy.MaxAttempts != 5 || policy.Delay != 50*time.Millisecond || policy.Strategy != retry.StrategyNameLinear ||
+		policy.Jitter == nil || *policy.Jitter != 0 || !slices.Equal(policy.RetryableCodes, []int{503}) {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+}
+
+func TestPolicies_LoadFromConfigFile(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"retry.yaml": `
+retry:
+  default:
+    max_attempts: 2
+  db:
+    max_attempts: 5
+    delay: 50ms
+    max_delay: 2s
+    strategy: decorrelated-jitter
+  http-external:
+    delay: 100ms
+    attempt_timeout: 1.5s
+    jitter: 0
+    retryable_codes: [503]
+    retryable_grpc_codes: [UNAVAILABLE]
+`,
+		"retry.json": `{"retry": {
+  "default": {"max_attempts": 2},
+  "db": {"max_attempts": 5, "delay": "50ms", "max_delay": "2s", "strategy": "decorrelated-jitter"},
+  "http-external": {"delay": "100ms", "attempt_timeout": "1.5s", "jitter": 0,
+    "retryable_codes": [503], "retryable_grpc_codes": ["UNAVAILABLE"]}
+}}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			var parser koanf.Parser = yaml.Parser()
+			if filepath.Ext(name) == ".json" {
+				parser = koanfjson.Parser()
+			}
+
+			// Load the file the way configloader.WithFile and Load do.
+			k := koanf.New(".")
+			if err := k.Load(file.Provider(path), parser); err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			var cfg struct {
+				Retry retry.Policies `koanf:"retry"`
+			}
+			if err := k.Unmarshal("", &cfg); err != nil {
+				t.Fatalf("failed to unmarshal policies: %v", err)
+			}
+
+			if err := cfg.Retry.Validate(); err != nil {
+				t.Fatalf("expected valid policies, got %v", err)
+			}
+
+			db := cfg.Retry.Get("db")
+			if db.MaxAttempts != 5 || db.Delay != 50*time.Millisecond || db.MaxDelay != 2*time.Second ||
+				db.Strategy != retry.StrategyNameDecorrelatedJitter || db.Jitter != nil {
+				t.Fatalf("unexpected db policy %+v", db)
+			}
+
+			external := cfg.Retry.Get("http-external")
+			if external.AttemptTimeout != 1500*time.Millisecond || external.Jitter == nil || *external.Jitter != 0 ||
+				!slices.Equal(external.RetryableCodes, []int{503}) ||
+				!slices.Equal(external.RetryableGRPCCodes, []string{"UNAVAILABLE"}) {
+				t.Fatalf("unexpected http-external policy %+v", external)
+			}
+
+			if got := cfg.Retry.Get("cache").MaxAttempts; got != 2 {
+				t.Fatalf("expected the default policy, got %d attempts", got)
+			}
+		})
+	}
+}
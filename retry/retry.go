@@ -3,8 +3,7 @@ package retry
 
 import (
 	"context"
-	"math"
-	"math/rand/v2"
+	"errors"
 	"time"
 )
 
@@ -12,14 +11,20 @@ import (
 type Strategy int
 
 const (
-	// StrategyConstant uses a fixed delay between attempts.
+	// StrategyConstant uses a fixed delay between attempts (see Constant).
 	StrategyConstant Strategy = iota
-	// StrategyLinear increases the delay linearly (delay * attempt).
+	// StrategyLinear increases the delay linearly (delay * attempt, see Linear).
 	StrategyLinear
-	// StrategyExponential doubles the delay on each attempt.
+	// StrategyExponential doubles the delay on each attempt (see Exponential).
 	StrategyExponential
+	// StrategyFibonacci grows the delay along the Fibonacci sequence (see Fibonacci).
+	StrategyFibonacci
 )
 
+// ErrMaxElapsedTime is the RetryError cause when retrying stops because the
+// WithMaxElapsedTime budget would be exceeded.
+var ErrMaxElapsedTime = errors.New("max elapsed time exceeded")
+
 const (
 	defaultMaxAttempts = 3
 	defaultDelay       = 100 * time.Millisecond
@@ -31,8 +36,16 @@ type config struct {
 	maxAttempts int
 	delay       time.Duration
 	maxDelay    time.Duration
+	maxElapsed  time.Duration
+	timeout     time.Duration
 	strategy    Strategy
 	jitter      bool
+	jitterFrac  float64
+	backoff     Backoff
+	retryIf     func(err error) bool
+	budget      *Budget
+	clock       Clock
+	onRetry     func(ctx context.Context, attempt Attempt)
 }
 
 // Option configures the retry behavior.
@@ -64,6 +77,25 @@ func WithMaxDelay(d time.Duration) Option {
 	}
 }
 
+// WithMaxElapsedTime limits the total time spent retrying. Do stops before
+// a delay that would end past d and returns a *RetryError matching both
+// ErrMaxElapsedTime and the attempts' errors.
+// Default: 0 (no limit).
+func WithMaxElapsedTime(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxElapsed = d
+	}
+}
+
+// WithAttemptTimeout gives each call of fn a context that expires after d.
+// An attempt that times out is retried like any other failure.
+// Default: 0 (attempts share the caller's context).
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.timeout = d
+	}
+}
+
 // WithStrategy sets the backoff strategy.
 // Default: StrategyExponential.
 func WithStrategy(s Strategy) Option {
@@ -72,7 +104,7 @@ func WithStrategy(s Strategy) Option {
 	}
 }
 
-// WithJitter enables or disables random ±25% jitter on the delay.
+// WithJitter enables or disables random jitter on the delay.
 // Default: true.
 func WithJitter(enabled bool) Option {
 	return func(cfg *config) {
@@ -80,66 +112,142 @@ func WithJitter(enabled bool) Option {
 	}
 }
 
+// WithJitterFraction sets how far jitter may move the delay, as a fraction
+// between 0 and 1 of it; 0 disables jitter. Other values are ignored.
+// Default: 0.25 (±25%).
+func WithJitterFraction(fraction float64) Option {
+	return func(cfg *config) {
+		if fraction >= 0 && fraction <= 1 {
+			cfg.jitterFrac = fraction
+			cfg.jitter = fraction > 0
+		}
+	}
+}
+
 // Do executes fn, retrying on error according to the configured policy.
-// It respects context cancellation between attempts.
-// Returns the last error if all attempts fail or the context is cancelled.
+// It respects context cancellation between attempts. fn can read the attempt
+// number from its context with AttemptFromContext. Errors wrapped with
+// Permanent, Retryable errors that are not temporary and errors rejected by
+// WithRetryIf are returned without retrying.
+// Returns a *RetryError holding every attempt's error if all attempts fail,
+// retrying stops early or the context is cancelled.
 func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
-	cfg := &config{
-		maxAttempts: defaultMaxAttempts,
-		delay:       defaultDelay,
-		maxDelay:    defaultMaxDelay,
-		strategy:    StrategyExponential,
-		jitter:      true,
-	}
+	_, err := DoValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, opts...)
 
-	for _, opt := range opts {
-		opt(cfg)
+	return err
+}
+
+// DoValue executes fn like Do and returns the value of the first successful
+// attempt. On failure it returns the zero value of T and the same error Do would.
+func DoValue[T any](ctx context.Context, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	cfg := newConfig(opts)
+
+	var (
+		zero     T
+		delay    time.Duration
+		attempts []Attempt
+		start    = cfg.clock.Now()
+	)
+
+	if cfg.budget != nil {
+		cfg.budget.request()
 	}
 
-	var lastErr error
 	for attempt := range cfg.maxAttempts {
-		lastErr = fn(ctx)
-		if lastErr == nil {
-			return nil
+		value, err := runAttempt(ctx, cfg, fn, attempt)
+		if err == nil {
+			return value, nil
 		}
 
-		// Don't sleep after the last attempt.
-		if attempt == cfg.maxAttempts-1 {
+		attempts = append(attempts, Attempt{Number: attempt + 1, Err: err, Elapsed: cfg.clock.Now().Sub(start)})
+
+		// Don't sleep after the last attempt or for errors that won't succeed.
+		if attempt == cfg.maxAttempts-1 || !shouldRetry(cfg, err) {
 			break
 		}
 
-		delay := computeDelay(cfg, attempt)
+		delay = computeDelay(cfg, attempt, delay)
+		if requested, ok := serverDelay(err); ok {
+			delay = min(requested, cfg.maxDelay)
+		}
+
+		if elapsed := cfg.clock.Now().Sub(start); cfg.maxElapsed > 0 && elapsed+delay > cfg.maxElapsed {
+			return zero, &RetryError{Attempts: attempts, Cause: ErrMaxElapsedTime}
+		}
+
+		if cfg.budget != nil && !cfg.budget.withdraw() {
+			return zero, &RetryError{Attempts: attempts, Cause: ErrBudgetExhausted}
+		}
+
+		attempts[attempt].NextDelay = delay
+		if cfg.onRetry != nil {
+			cfg.onRetry(ctx, attempts[attempt])
+		}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
+		if err := sleep(ctx, cfg.clock, delay); err != nil {
+			return zero, &RetryError{Attempts: attempts, Cause: err}
 		}
 	}
 
-	return lastErr
+	return zero, &RetryError{Attempts: attempts}
+}
+
+// sleep waits for d on clock, stopping the timer if ctx is done first.
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // recorded as the RetryError cause
+	case <-timer.C():
+		return nil
+	}
+}
+
+// runAttempt calls fn with the attempt's context.
+func runAttempt[T any](ctx context.Context, cfg *config, fn func(ctx context.Context) (T, error), attempt int) (T, error) {
+	ctx = withAttempt(ctx, attempt+1, attempt == cfg.maxAttempts-1)
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	return fn(ctx)
 }
 
-// computeDelay calculates the backoff delay for the given attempt number.
-func computeDelay(cfg *config, attempt int) time.Duration {
-	var delay time.Duration
+// newConfig applies opts on top of the defaults.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		maxAttempts: defaultMaxAttempts,
+		delay:       defaultDelay,
+		maxDelay:    defaultMaxDelay,
+		strategy:    StrategyExponential,
+		jitter:      true,
+		jitterFrac:  jitterFraction,
+		clock:       systemClock{},
+	}
 
-	switch cfg.strategy {
-	case StrategyConstant:
-		delay = cfg.delay
-	case StrategyLinear:
-		delay = cfg.delay * time.Duration(attempt+1)
-	case StrategyExponential:
-		//nolint:gosec // math.Pow on small ints is safe
-		delay = cfg.delay * time.Duration(math.Pow(2, float64(attempt)))
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	if cfg.jitter {
-		delta := float64(delay) * jitterFraction
-		jitterVal := (rand.Float64()*2 - 1) * delta //nolint:gosec // jitter does not need crypto rand
-		delay += time.Duration(jitterVal)
+	if cfg.backoff == nil {
+		cfg.backoff = strategyBackoff(cfg)
 	}
 
+	return cfg
+}
+
+// computeDelay calculates the backoff delay for the given attempt number,
+// given the previous delay.
+func computeDelay(cfg *config, attempt int, prev time.Duration) time.Duration {
+	delay := cfg.backoff.Next(attempt, prev)
+
 	if delay > cfg.maxDelay {
 		delay = cfg.maxDelay
 	}
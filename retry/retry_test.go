@@ -3,10 +3,12 @@ package retry_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/GabrielNunesIT/go-libs/retry"
+	"github.com/GabrielNunesIT/go-libs/retry/retrytest"
 )
 
 var errTransient = errors.New("transient failure")
@@ -162,3 +164,131 @@ func TestDo_DefaultOptions(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 }
+
+func TestDoValue_ReturnsValueOfSuccessfulAttempt(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	got, err := retry.DoValue(context.Background(), func(_ context.Context) (string, error) {
+		calls++
+		if calls < 2 {
+			return "partial", errTransient
+		}
+		return "done", nil
+	}, retry.WithDelay(time.Millisecond), retry.WithJitter(false))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got != "done" {
+		t.Fatalf("expected %q, got %q", "done", got)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestDoValue_ReturnsZeroValueOnFailure(t *testing.T) {
+	t.Parallel()
+
+	got, err := retry.DoValue(context.Background(), func(_ context.Context) (int, error) {
+		return 42, errTransient
+	}, retry.WithMaxAttempts(2), retry.WithDelay(time.Millisecond))
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected errTransient, got %v", err)
+	}
+	if got != 0 {
+		t.Fatalf("expected zero value, got %d", got)
+	}
+}
+
+func TestDoValue_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	got, err := retry.DoValue(ctx, func(_ context.Context) (*int, error) {
+		cancel()
+		return new(int), errTransient
+	}, retry.WithDelay(time.Second))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected nil result, got %v", got)
+	}
+}
+
+func TestWithMaxElapsedTime(t *testing.T) {
+	t.Parallel()
+
+	clock := retrytest.NewFakeClock(time.Unix(0, 0))
+	calls := 0
+	done := make(chan error, 1)
+
+	go func() {
+		done <- retry.Do(context.Background(), func(_ context.Context) error {
+			calls++
+			return errTransient
+		},
+			retry.WithClock(clock),
+			retry.WithMaxAttempts(100),
+			retry.WithBackoff(retry.Constant{Delay: 20 * time.Millisecond}),
+			retry.WithMaxElapsedTime(50*time.Millisecond),
+		)
+	}()
+
+	// The third attempt ends at 40ms; another 20ms wait would exceed 50ms.
+	for range 2 {
+		clock.BlockUntil(1)
+		clock.Advance(20 * time.Millisecond)
+	}
+	err := <-done
+
+	if !errors.Is(err, retry.ErrMaxElapsedTime) {
+		t.Fatalf("expected ErrMaxElapsedTime, got %v", err)
+	}
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected error to wrap errTransient, got %v", err)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "retry: max elapsed time exceeded after 3 failed attempts") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls within the budget, got %d", calls)
+	}
+
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %v", err)
+	}
+	if elapsed := retryErr.Attempts[2].Elapsed; elapsed != 40*time.Millisecond {
+		t.Fatalf("expected to stop after 40ms of fake time, got %v", elapsed)
+	}
+}
+
+func TestWithAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected attempt context to have a deadline")
+		}
+		return nil
+	}, retry.WithAttemptTimeout(10*time.Millisecond), retry.WithDelay(time.Millisecond))
+
+	if err != nil {
+		t.Fatalf("expected the second attempt to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
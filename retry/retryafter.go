@@ -0,0 +1,86 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delayer is implemented by errors that carry the delay a server asked for
+// before the next attempt. The delay replaces the computed backoff, capped
+// by WithMaxDelay.
+type Delayer interface {
+	RetryAfter() time.Duration
+}
+
+// RetryAfterError attaches a server-provided delay to an error.
+// It unwraps to the original cause.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+// RetryAfter wraps err so that Do waits d before the next attempt instead of
+// the computed backoff. Returns nil if err is nil.
+func RetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return &RetryAfterError{Err: err, Delay: d}
+}
+
+// Error implements the error interface.
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original cause.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter implements Delayer.
+func (e *RetryAfterError) RetryAfter() time.Duration {
+	return e.Delay
+}
+
+// ParseRetryAfter reads the Retry-After header of resp, given either in
+// seconds or as an HTTP date. It reports false if the header is missing or
+// malformed. Dates in the past yield 0.
+func ParseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(date), 0), true
+}
+
+// serverDelay returns the delay requested through a Delayer in err's chain.
+func serverDelay(err error) (time.Duration, bool) {
+	var delayer Delayer
+	if !errors.As(err, &delayer) {
+		return 0, false
+	}
+
+	return max(delayer.RetryAfter(), 0), true
+}
@@ -0,0 +1,117 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+func TestRetryAfter_OverridesComputedDelay(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	calls := 0
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		calls++
+		if calls == 1 {
+			return retry.RetryAfter(errTransient, 30*time.Millisecond)
+		}
+		return nil
+	}, retry.WithDelay(time.Millisecond), retry.WithJitter(false))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected to wait the requested 30ms, elapsed %v", elapsed)
+	}
+}
+
+func TestRetryAfter_CappedByMaxDelay(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	err := retry.Do(context.Background(), func(_ context.Context) error {
+		return retry.RetryAfter(errTransient, time.Hour)
+	}, retry.WithMaxAttempts(2), retry.WithMaxDelay(10*time.Millisecond))
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("expected errTransient, got %v", err)
+	}
+
+	var delayer retry.Delayer
+	if !errors.As(err, &delayer) || delayer.RetryAfter() != time.Hour {
+		t.Fatalf("expected error to carry the requested delay, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the delay to be capped, elapsed %v", elapsed)
+	}
+}
+
+func TestRetryAfter_RespectsContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := retry.Do(ctx, func(_ context.Context) error {
+		return retry.RetryAfter(errTransient, time.Minute)
+	}, retry.WithMaxDelay(time.Hour))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"Seconds", "120", 2 * time.Minute, true},
+		{"Zero", "0", 0, true},
+		{"PastDate", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"Missing", "", 0, false},
+		{"Negative", "-5", 0, false},
+		{"Malformed", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+
+			got, ok := retry.ParseRetryAfter(resp)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("expected (%v, %v), got (%v, %v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter_FutureDate(t *testing.T) {
+	t.Parallel()
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+
+	got, ok := retry.ParseRetryAfter(resp)
+	if !ok {
+		t.Fatal("expected the date to parse")
+	}
+	if got <= 58*time.Minute || got > time.Hour {
+		t.Fatalf("expected about 1h, got %v", got)
+	}
+}
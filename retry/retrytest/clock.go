@@ -0,0 +1,131 @@
+// Package retrytest provides a fake clock for testing code that uses the
+// retry package without sleeping.
+package retrytest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+// FakeClock is a retry.Clock that only moves when Advance is called.
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+}
+
+var _ retry.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	clock := &FakeClock{
+		now:    start,
+		timers: make(map[*fakeTimer]struct{}),
+	}
+	clock.cond = sync.NewCond(&clock.mu)
+
+	return clock
+}
+
+// Now implements retry.Clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer implements retry.Clock. A timer for d <= 0 fires immediately.
+//
+//nolint:ireturn // implements retry.Clock
+func (c *FakeClock) NewTimer(d time.Duration) retry.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.ch <- c.now
+		return timer
+	}
+
+	c.timers[timer] = struct{}{}
+	c.cond.Broadcast()
+
+	return timer
+}
+
+// Advance moves the clock forward by d and fires every timer due by then.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for timer := range c.timers {
+		if !timer.deadline.After(c.now) {
+			delete(c.timers, timer)
+			timer.ch <- c.now
+		}
+	}
+	c.cond.Broadcast()
+}
+
+// Timers returns the number of pending timers.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until n timers are pending, for example until Do is
+// waiting before its next attempt.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// NextDeadline returns how far the earliest pending timer is from now, and
+// false if no timer is pending.
+func (c *FakeClock) NextDeadline() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		next  time.Duration
+		found bool
+	)
+	for timer := range c.timers {
+		if d := timer.deadline.Sub(c.now); !found || d < next {
+			next, found = d, true
+		}
+	}
+
+	return next, found
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	_, pending := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	t.clock.cond.Broadcast()
+
+	return pending
+}
@@ -0,0 +1,192 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxDrainBytes bounds how much of a discarded response body is read so the
+// connection can be reused.
+const maxDrainBytes = 64 << 10
+
+// StatusError is the attempt error recorded when Transport retries a
+// response because of its status code.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return "retry: server responded " + e.Status
+}
+
+// connError marks a connection error as temporary, overriding errors such as
+// *net.OpError that report false from Temporary for refused connections.
+type connError struct {
+	err error
+}
+
+func (e connError) Error() string   { return e.err.Error() }
+func (e connError) Unwrap() error   { return e.err }
+func (e connError) Temporary() bool { return true }
+
+type transport struct {
+	base        http.RoundTripper
+	options     []Option
+	statusCodes map[int]bool
+}
+
+// TransportOption configures the RoundTripper returned by Transport.
+type TransportOption func(*transport)
+
+// WithRetryOptions sets the options passed to Do for every request.
+func WithRetryOptions(opts ...Option) TransportOption {
+	return func(t *transport) {
+		t.options = append(t.options, opts...)
+	}
+}
+
+// WithRetryStatusCodes sets the response status codes that are retried.
+// Default: 429, 502, 503 and 504.
+func WithRetryStatusCodes(codes ...int) TransportOption {
+	return func(t *transport) {
+		t.statusCodes = make(map[int]bool, len(codes))
+		for _, code := range codes {
+			t.statusCodes[code] = true
+		}
+	}
+}
+
+// Transport wraps base with retries. Connection errors and responses with a
+// retryable status code are retried for idempotent methods (GET, HEAD,
+// OPTIONS, TRACE, PUT, DELETE) and for requests carrying an Idempotency-Key
+// header; other requests are sent once. Request bodies are rewound with
+// GetBody, so requests with a body but no GetBody are sent once as well.
+// A retryable response's Retry-After header replaces the computed delay, and
+// the response is kept until the next attempt starts, then drained and
+// closed. If no further attempt is made, because the attempts ran out or
+// retrying stopped early (for example WithMaxElapsedTime or WithBudget), the
+// last response is returned to the caller instead of an error. If base is
+// nil, http.DefaultTransport is used.
+//
+// WithAttemptTimeout is ignored by the transport, because the attempt context
+// would end before the caller reads the response body; use
+// http.Client.Timeout or the request context instead.
+//
+//nolint:ireturn // designed to be assigned to http.Client.Transport
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	t := &transport{
+		base: base,
+		statusCodes: map[int]bool{
+			http.StatusTooManyRequests:    true,
+			http.StatusBadGateway:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	t.options = append(t.options, WithAttemptTimeout(0))
+
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !replayable(req) {
+		return t.base.RoundTrip(req) //nolint:wrapcheck // transport errors are returned unchanged
+	}
+
+	// last holds the most recent retryable response until Do either starts
+	// another attempt or gives up.
+	var last *http.Response
+
+	resp, err := DoValue(req.Context(), func(ctx context.Context) (*http.Response, error) {
+		if last != nil {
+			drain(last)
+			last = nil
+		}
+
+		attemptReq, err := t.prepare(ctx, req)
+		if err != nil {
+			return nil, Permanent(err)
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			if req.Context().Err() != nil {
+				return nil, Permanent(err)
+			}
+			return nil, connError{err: err}
+		}
+
+		if !t.statusCodes[resp.StatusCode] || IsLastAttempt(ctx) {
+			return resp, nil
+		}
+
+		delay, hasDelay := ParseRetryAfter(resp)
+		last = resp
+
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		if hasDelay {
+			return nil, RetryAfter(statusErr, delay)
+		}
+
+		return nil, statusErr
+	}, t.options...)
+
+	if err != nil && last != nil {
+		if req.Context().Err() == nil {
+			return last, nil
+		}
+		drain(last)
+	}
+
+	return resp, err
+}
+
+// prepare returns the request for an attempt, rewinding the body after the
+// first attempt.
+func (t *transport) prepare(ctx context.Context, req *http.Request) (*http.Request, error) {
+	attemptReq := req.Clone(ctx)
+
+	if n, _ := AttemptFromContext(ctx); n > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("retry: rewind request body: %w", err)
+		}
+		attemptReq.Body = body
+	}
+
+	return attemptReq, nil
+}
+
+// replayable reports whether req may be sent more than once.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete, "":
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+// drain reads a bounded amount of the body and closes it so the connection
+// can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,247 @@
+package retry_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GabrielNunesIT/go-libs/retry"
+)
+
+// flakyServer answers with failStatus for the first failures requests and
+// 200 afterwards, recording every request body it receives.
+type flakyServer struct {
+	*httptest.Server
+
+	calls  atomic.Int64
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newFlakyServer(t *testing.T, failures int64, failStatus int, header http.Header) *flakyServer {
+	t.Helper()
+
+	fs := &flakyServer{}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fs.mu.Lock()
+		fs.bodies = append(fs.bodies, string(body))
+		fs.mu.Unlock()
+
+		if fs.calls.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(failStatus)
+			_, _ = io.WriteString(w, "try again later")
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(fs.Close)
+
+	return fs
+}
+
+func newRetryClient(opts ...retry.TransportOption) *http.Client {
+	opts = append([]retry.TransportOption{
+		retry.WithRetryOptions(retry.WithMaxAttempts(3), retry.WithDelay(time.Millisecond)),
+	}, opts...)
+
+	return &http.Client{Transport: retry.Transport(nil, opts...)}
+}
+
+func send(t *testing.T, client *http.Client, method, url, body string, header http.Header) (*http.Response, string, error) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), method, url, reader)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	return resp, string(data), nil
+}
+
+func TestTransport_RetriesIdempotentRequests(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 2, http.StatusServiceUnavailable, nil)
+
+	resp, body, err := send(t, newRetryClient(), http.MethodGet, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || body != "ok" {
+		t.Fatalf("expected 200 ok, got %d %q", resp.StatusCode, body)
+	}
+	if got := server.calls.Load(); got != 3 {
+		t.Fatalf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestTransport_RewindsBody(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 1, http.StatusBadGateway, nil)
+
+	_, _, err := send(t, newRetryClient(), http.MethodPut, server.URL, "payload", nil)
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.bodies) != 2 || server.bodies[0] != "payload" || server.bodies[1] != "payload" {
+		t.Fatalf("expected the body to be sent twice, got %q", server.bodies)
+	}
+}
+
+func TestTransport_NonIdempotentRequests(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WithoutKey", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFlakyServer(t, 1, http.StatusServiceUnavailable, nil)
+
+		resp, _, err := send(t, newRetryClient(), http.MethodPost, server.URL, "order", nil)
+		if err != nil {
+			t.Fatalf("expected the response, got %v", err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", resp.StatusCode)
+		}
+		if got := server.calls.Load(); got != 1 {
+			t.Fatalf("expected POST to be sent once, got %d", got)
+		}
+	})
+
+	t.Run("WithIdempotencyKey", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFlakyServer(t, 1, http.StatusServiceUnavailable, nil)
+
+		header := http.Header{"Idempotency-Key": {"order-42"}}
+		resp, _, err := send(t, newRetryClient(), http.MethodPost, server.URL, "order", header)
+		if err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		if got := server.calls.Load(); got != 2 {
+			t.Fatalf("expected POST to be retried once, got %d", got)
+		}
+	})
+}
+
+func TestTransport_ReturnsLastResponse(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 10, http.StatusTooManyRequests, nil)
+
+	resp, body, err := send(t, newRetryClient(), http.MethodGet, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected the last response, got %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || body != "try again later" {
+		t.Fatalf("expected readable 429 response, got %d %q", resp.StatusCode, body)
+	}
+	if got := server.calls.Load(); got != 3 {
+		t.Fatalf("expected 3 requests, got %d", got)
+	}
+}
+
+func TestTransport_ReturnsResponseWhenStoppedEarly(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 10, http.StatusServiceUnavailable, http.Header{"Retry-After": {"10"}})
+
+	client := newRetryClient(retry.WithRetryOptions(retry.WithMaxElapsedTime(time.Second)))
+	resp, body, err := send(t, client, http.MethodGet, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected the response that stopped retrying, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || body != "try again later" {
+		t.Fatalf("expected readable 503 response, got %d %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "10" {
+		t.Fatalf("expected the Retry-After header to be kept, got %q", got)
+	}
+	if got := server.calls.Load(); got != 1 {
+		t.Fatalf("expected 1 request, got %d", got)
+	}
+}
+
+func TestTransport_HonoursRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"1"}})
+
+	start := time.Now()
+	if _, _, err := send(t, newRetryClient(), http.MethodGet, server.URL, "", nil); err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for Retry-After, elapsed %v", elapsed)
+	}
+}
+
+func TestTransport_CustomStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	server := newFlakyServer(t, 1, http.StatusInternalServerError, nil)
+
+	resp, _, err := send(t, newRetryClient(retry.WithRetryStatusCodes(http.StatusInternalServerError)),
+		http.MethodGet, server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestTransport_RetriesConnectionErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close() // connection refused from now on
+
+	_, _, err := send(t, newRetryClient(), http.MethodGet, url, "", nil)
+
+	var retryErr *retry.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected *RetryError, got %v", err)
+	}
+	if len(retryErr.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(retryErr.Attempts))
+	}
+}